@@ -15,6 +15,11 @@
 package analysis
 
 import (
+	"fmt"
+	"runtime/debug"
+
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/galley/pkg/config/processing/transformer"
 	"istio.io/istio/galley/pkg/config/scope"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -44,8 +49,9 @@ func Combine(name string, analyzers ...Analyzer) *CombinedAnalyzer {
 // Metadata implements Analyzer
 func (c *CombinedAnalyzer) Metadata() Metadata {
 	return Metadata{
-		Name:   c.name,
-		Inputs: combineInputs(c.analyzers),
+		Name:     c.name,
+		Inputs:   combineInputs(c.analyzers),
+		Messages: combineMessages(c.analyzers),
 	}
 }
 
@@ -57,11 +63,47 @@ func (c *CombinedAnalyzer) Analyze(ctx Context) {
 			scope.Analysis.Debugf("Analyzer %q has been cancelled...", c.Metadata().Name)
 			return
 		}
-		a.Analyze(ctx)
+		analyze(a, ctx)
 		scope.Analysis.Debugf("Completed analyzer %q...", a.Metadata().Name)
 	}
 }
 
+// analyze runs an analyzer, attributing the messages it reports to it. If the analyzer panics, the panic is reported
+// as an internal error attributed to it instead, so that a bug in one analyzer doesn't stop the others from running.
+func analyze(a Analyzer, ctx Context) {
+	name := a.Metadata().Name
+	defer func() {
+		if r := recover(); r != nil {
+			scope.Analysis.Errorf("Analyzer %q panicked: %v", name, r)
+			m := msg.NewInternalError(nil, fmt.Sprintf("analyzer %q panicked: %v", name, r))
+			m.Analyzer = name
+			m.Stack = string(debug.Stack())
+			ctx.Report("", m)
+		}
+	}()
+	a.Analyze(&attributingContext{Context: ctx, analyzer: name})
+}
+
+// attributingContext attributes the messages reported through it to an analyzer, unless they are already attributed,
+// e.g. by an analyzer nested in another combined analyzer.
+type attributingContext struct {
+	Context
+	analyzer string
+}
+
+// Report implements Context
+func (c *attributingContext) Report(col collection.Name, m diag.Message) {
+	if m.Analyzer == "" {
+		m.Analyzer = c.analyzer
+	}
+	c.Context.Report(col, m)
+}
+
+// Unwrap returns the context the messages are reported to
+func (c *attributingContext) Unwrap() Context {
+	return c.Context
+}
+
 // RemoveSkipped removes analyzers that should be skipped, meaning they meet one of the following criteria:
 // 1. The analyzer requires disabled input collections. The names of removed analyzers are returned.
 // Transformer information is used to determine, based on the disabled input collections, which output collections
@@ -120,6 +162,21 @@ func combineInputs(analyzers []Analyzer) collection.Names {
 	return result
 }
 
+// combineMessages returns the message types of all the analyzers, without duplicates
+func combineMessages(analyzers []Analyzer) []*diag.MessageType {
+	var result []*diag.MessageType
+	seen := make(map[*diag.MessageType]bool)
+	for _, a := range analyzers {
+		for _, mt := range a.Metadata().Messages {
+			if !seen[mt] {
+				seen[mt] = true
+				result = append(result, mt)
+			}
+		}
+	}
+	return result
+}
+
 func getDisabledOutputs(disabledInputs collection.Names, xformProviders transformer.Providers) map[collection.Name]struct{} {
 	// Get disabledCollections as a set
 	disabledInputSet := make(map[collection.Name]struct{})
@@ -20,6 +20,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/galley/pkg/config/processing"
 	"istio.io/istio/galley/pkg/config/processing/transformer"
 	"istio.io/istio/pkg/config/event"
@@ -29,27 +30,39 @@ import (
 )
 
 type analyzer struct {
-	name   string
-	inputs collection.Names
-	ran    bool
+	name     string
+	inputs   collection.Names
+	messages []*diag.MessageType
+	reports  []diag.Message
+	panics   bool
+	ran      bool
 }
 
 // Metadata implements Analyzer
 func (a *analyzer) Metadata() Metadata {
 	return Metadata{
-		Name:   a.name,
-		Inputs: a.inputs,
+		Name:     a.name,
+		Inputs:   a.inputs,
+		Messages: a.messages,
 	}
 }
 
 // Analyze implements Analyzer
-func (a *analyzer) Analyze(Context) {
+func (a *analyzer) Analyze(ctx Context) {
 	a.ran = true
+	for _, m := range a.reports {
+		ctx.Report("", m)
+	}
+	if a.panics {
+		panic("boom")
+	}
 }
 
-type context struct{}
+type context struct {
+	messages diag.Messages
+}
 
-func (ctx *context) Report(collection.Name, diag.Message)                       {}
+func (ctx *context) Report(_ collection.Name, m diag.Message)                   { ctx.messages.Add(m) }
 func (ctx *context) Find(collection.Name, resource.FullName) *resource.Instance { return nil }
 func (ctx *context) Exists(collection.Name, resource.FullName) bool             { return false }
 func (ctx *context) ForEach(collection.Name, IteratorFn)                        {}
@@ -89,6 +102,47 @@ func TestCombinedAnalyzer(t *testing.T) {
 	g.Expect(a4.ran).To(BeFalse())
 }
 
+func TestCombinedAnalyzer_AttributesMessages(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := diag.NewMessageType(diag.Error, "IST-0-0", "Template")
+	preattributed := diag.NewMessage(mt, nil)
+	preattributed.Analyzer = "original"
+
+	a1 := &analyzer{name: "a1", reports: []diag.Message{diag.NewMessage(mt, nil), preattributed}}
+	a2 := &analyzer{name: "a2", reports: []diag.Message{diag.NewMessage(mt, nil)}}
+	nested := &analyzer{name: "nested", reports: []diag.Message{diag.NewMessage(mt, nil)}}
+
+	ctx := &context{}
+	Combine("combined", a1, a2, Combine("inner", nested)).Analyze(ctx)
+
+	var analyzers []string
+	for _, m := range ctx.messages {
+		analyzers = append(analyzers, m.Analyzer)
+	}
+	g.Expect(analyzers).To(Equal([]string{"a1", "original", "a2", "nested"}))
+}
+
+func TestCombinedAnalyzer_RecoversPanics(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := diag.NewMessageType(diag.Error, "IST-0-0", "Template")
+	a1 := &analyzer{name: "a1", reports: []diag.Message{diag.NewMessage(mt, nil)}, panics: true}
+	a2 := &analyzer{name: "a2"}
+
+	ctx := &context{}
+	Combine("combined", a1, a2).Analyze(ctx)
+
+	g.Expect(a2.ran).To(BeTrue())
+	g.Expect(ctx.messages).To(HaveLen(2))
+	g.Expect(ctx.messages[0].Type).To(Equal(mt))
+	recovered := ctx.messages[1]
+	g.Expect(recovered.Type).To(Equal(msg.InternalError))
+	g.Expect(recovered.Analyzer).To(Equal("a1"))
+	g.Expect(recovered.Text()).To(Equal(`Internal error: analyzer "a1" panicked: boom`))
+	g.Expect(recovered.Stack).To(ContainSubstring("analyzer_test.go"))
+}
+
 func TestGetDisabledOutputs(t *testing.T) {
 	g := NewWithT(t)
 
@@ -68,7 +68,7 @@ type testCase struct {
 // * The resources in the input files don't necessarily need to be completely defined, just defined enough for the analyzer being tested.
 // * Please keep this list sorted alphabetically by the pkg.name of the analyzer for convenience
 // * Expected messages are in the format {msg.ValidationMessageType, "<ResourceKind>/<Namespace>/<ResourceName>"}.
-//     * Note that if Namespace is omitted in the input YAML, it will be skipped here.
+//   - Note that if Namespace is omitted in the input YAML, it will be skipped here.
 var testGrid = []testCase{
 	{
 		name: "misannoted",
@@ -605,6 +605,12 @@ func TestAnalyzers(t *testing.T) {
 			}
 
 			g.Expect(extractFields(result.Messages)).To(ConsistOf(tc.expected), "%v", prettyPrintMessages(result.Messages))
+
+			// Every message reported must be of a type declared in the metadata of the analyzer
+			for _, m := range result.Messages {
+				g.Expect(tc.analyzer.Metadata().Messages).To(ContainElement(m.Type), fmt.Sprintf(
+					"Metadata messages for analyzer %q don't include %s, which it reported.", analyzerName, m.Type.Code()))
+			}
 		})
 	}
 
@@ -640,6 +646,66 @@ func TestAnalyzers(t *testing.T) {
 }
 
 // Verify that all of the analyzers tested here are also registered in All()
+// Verify that messages about resources that refer to others relate the ones that exist
+func TestAnalyzersRelated(t *testing.T) {
+	cases := []struct {
+		name       string
+		inputFiles []string
+		analyzer   analysis.Analyzer
+		// The related origins of each message, keyed by the code and origin of the message
+		expected map[string][]string
+	}{
+		{
+			name:       "virtualServiceDestinationRules",
+			inputFiles: []string{"testdata/virtualservice_destinationrules.yaml"},
+			analyzer:   &virtualservice.DestinationRuleAnalyzer{},
+			expected: map[string][]string{
+				"IST0101 VirtualService reviews-bogussubset.default":        {"DestinationRule reviews.default testdata/virtualservice_destinationrules.yaml:1"},
+				"IST0101 VirtualService reviews-mirror-bogussubset.default": {"DestinationRule reviews.default testdata/virtualservice_destinationrules.yaml:1"},
+			},
+		},
+		{
+			name:       "virtualServiceGateways",
+			inputFiles: []string{"testdata/virtualservice_gateways.yaml"},
+			analyzer:   &virtualservice.GatewayAnalyzer{},
+			expected: map[string][]string{
+				"IST0101 VirtualService httpbin-bogus":      nil,
+				"IST0132 VirtualService cross-test.default": {"Gateway crossnamespace-gw.another testdata/virtualservice_gateways.yaml:11"},
+				"IST0132 VirtualService httpbin-bogus":      nil,
+				"IST0132 VirtualService httpbin":            {"Gateway httpbin-gateway testdata/virtualservice_gateways.yaml:1"},
+			},
+		},
+		{
+			name:       "Route Rule no effect on Ingress",
+			inputFiles: []string{"testdata/virtualservice_route_rule_no_effects_ingress.yaml"},
+			analyzer:   &virtualservice.DestinationHostAnalyzer{},
+			expected: map[string][]string{
+				"IST0140 VirtualService testing-service-01-test-01.default": {
+					"VirtualService ratings-01.default testdata/virtualservice_route_rule_no_effects_ingress.yaml:76",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			sa, err := setupAnalyzerForCase(testCase{inputFiles: tc.inputFiles, analyzer: tc.analyzer}, nil)
+			g.Expect(err).To(BeNil())
+			result, err := runAnalyzer(sa)
+			g.Expect(err).To(BeNil())
+
+			related := make(map[string][]string)
+			for _, m := range result.Messages {
+				related[m.Type.Code()+" "+m.Resource.Origin.FriendlyName()] = m.RelatedOrigins()
+			}
+			g.Expect(related).To(Equal(tc.expected))
+		})
+	}
+}
+
 func TestAnalyzersInAll(t *testing.T) {
 	g := NewWithT(t)
 
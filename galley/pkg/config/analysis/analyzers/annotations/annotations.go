@@ -20,6 +20,7 @@ import (
 	"istio.io/api/annotation"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -43,6 +44,12 @@ func (*K8sAnalyzer) Metadata() analysis.Metadata {
 			collections.K8SCoreV1Pods.Name(),
 			collections.K8SAppsV1Deployments.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.DeprecatedAnnotation,
+			msg.InvalidAnnotation,
+			msg.MisplacedAnnotation,
+			msg.UnknownAnnotation,
+		},
 	}
 }
 
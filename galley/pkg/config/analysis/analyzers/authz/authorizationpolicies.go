@@ -25,6 +25,7 @@ import (
 	"istio.io/api/security/v1beta1"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -49,6 +50,10 @@ func (a *AuthorizationPoliciesAnalyzer) Metadata() analysis.Metadata {
 			collections.K8SCoreV1Namespaces.Name(),
 			collections.K8SCoreV1Pods.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.NoMatchingWorkloadsFound,
+			msg.ReferencedResourceNotFound,
+		},
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -55,6 +56,11 @@ func (s *ServiceAssociationAnalyzer) Metadata() analysis.Metadata {
 			collections.K8SAppsV1Deployments.Name(),
 			collections.K8SCoreV1Namespaces.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.DeploymentAssociatedToMultipleServices,
+			msg.DeploymentConflictingPorts,
+			msg.DeploymentRequiresServiceAssociated,
+		},
 	}
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -67,6 +68,9 @@ func (*FieldAnalyzer) Metadata() analysis.Metadata {
 		Description: "Checks for deprecated Istio types and fields",
 		Inputs: append(deprecationInputs,
 			collections.Deprecated.CollectionNames()...),
+		Messages: []*diag.MessageType{
+			msg.Deprecated,
+		},
 	}
 }
 
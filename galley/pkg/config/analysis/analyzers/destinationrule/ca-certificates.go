@@ -20,6 +20,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -38,6 +39,10 @@ func (c *CaCertificateAnalyzer) Metadata() analysis.Metadata {
 		Inputs: collection.Names{
 			collections.IstioNetworkingV1Alpha3Destinationrules.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.NoServerCertificateVerificationDestinationLevel,
+			msg.NoServerCertificateVerificationPortLevel,
+		},
 	}
 }
 
@@ -18,6 +18,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pkg/config/resource"
@@ -36,6 +37,9 @@ func (*CertificateAnalyzer) Metadata() analysis.Metadata {
 		Inputs: collection.Names{
 			collections.IstioNetworkingV1Alpha3Gateways.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.GatewayDuplicateCertificate,
+		},
 	}
 }
 
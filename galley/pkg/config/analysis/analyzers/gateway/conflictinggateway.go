@@ -24,6 +24,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/resource"
@@ -45,6 +46,10 @@ func (*ConflictingGatewayAnalyzer) Metadata() analysis.Metadata {
 		Inputs: collection.Names{
 			collections.IstioNetworkingV1Alpha3Gateways.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.ConflictingGateways,
+			msg.ReferencedResourceNotFound,
+		},
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -44,6 +45,9 @@ func (a *SecretAnalyzer) Metadata() analysis.Metadata {
 			collections.K8SCoreV1Pods.Name(),
 			collections.K8SCoreV1Secrets.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.ReferencedResourceNotFound,
+		},
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 	klabels "k8s.io/apimachinery/pkg/labels"
 
 	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -51,6 +52,10 @@ func (a *ImageAutoAnalyzer) Metadata() analysis.Metadata {
 			collections.K8SAppsV1Deployments.Name(),
 			collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.ImageAutoWithoutInjectionError,
+			msg.ImageAutoWithoutInjectionWarning,
+		},
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -59,6 +60,9 @@ func (a *ImageAnalyzer) Metadata() analysis.Metadata {
 			collections.K8SCoreV1Pods.Name(),
 			collections.K8SCoreV1Configmaps.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.IstioProxyImageMismatch,
+		},
 	}
 }
 
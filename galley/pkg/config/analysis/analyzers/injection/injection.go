@@ -25,6 +25,7 @@ import (
 	"istio.io/api/label"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/resource"
@@ -54,6 +55,12 @@ func (a *Analyzer) Metadata() analysis.Metadata {
 			collections.K8SCoreV1Pods.Name(),
 			collections.K8SCoreV1Configmaps.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.NamespaceInjectionEnabledByDefault,
+			msg.NamespaceMultipleInjectionLabels,
+			msg.NamespaceNotInjected,
+			msg.PodMissingProxy,
+		},
 	}
 }
 
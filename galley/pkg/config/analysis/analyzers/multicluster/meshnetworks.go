@@ -22,6 +22,7 @@ import (
 	"istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pilot/pkg/serviceregistry/provider"
 	"istio.io/istio/pkg/config/resource"
@@ -51,6 +52,9 @@ func (s *MeshNetworksAnalyzer) Metadata() analysis.Metadata {
 			collections.IstioMeshV1Alpha1MeshNetworks.Name(),
 			collections.K8SCoreV1Secrets.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.UnknownMeshNetworksServiceRegistry,
+		},
 	}
 }
 
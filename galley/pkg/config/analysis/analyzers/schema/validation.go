@@ -56,6 +56,12 @@ func (a *ValidationAnalyzer) Metadata() analysis.Metadata {
 		Name:        fmt.Sprintf("schema.ValidationAnalyzer.%s", a.s.Resource().Kind()),
 		Description: fmt.Sprintf("Runs schema validation as an analyzer on '%s' resources", a.s.Resource().Kind()),
 		Inputs:      collection.Names{a.s.Name()},
+		Messages: []*diag.MessageType{
+			msg.SchemaValidationError,
+			msg.SchemaWarning,
+			msg.VirtualServiceIneffectiveMatch,
+			msg.VirtualServiceUnreachableRule,
+		},
 	}
 }
 
@@ -21,6 +21,7 @@ import (
 
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	configKube "istio.io/istio/pkg/config/kube"
 	"istio.io/istio/pkg/config/resource"
@@ -41,6 +42,9 @@ func (s *PortNameAnalyzer) Metadata() analysis.Metadata {
 		Inputs: collection.Names{
 			collections.K8SCoreV1Services.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.PortNameIsNotUnderNamingConvention,
+		},
 	}
 }
 
@@ -16,6 +16,7 @@ package sidecar
 import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -38,6 +39,9 @@ func (a *DefaultSelectorAnalyzer) Metadata() analysis.Metadata {
 		Inputs: collection.Names{
 			collections.IstioNetworkingV1Alpha3Sidecars.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.MultipleSidecarsWithoutWorkloadSelectors,
+		},
 	}
 }
 
@@ -20,6 +20,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -46,6 +47,11 @@ func (a *DestinationHostAnalyzer) Metadata() analysis.Metadata {
 			collections.IstioNetworkingV1Alpha3Virtualservices.Name(),
 			collections.K8SCoreV1Services.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.IngressRouteRulesNotAffected,
+			msg.ReferencedResourceNotFound,
+			msg.VirtualServiceDestinationPortSelectorRequired,
+		},
 	}
 }
 
@@ -77,6 +83,9 @@ func (a *DestinationHostAnalyzer) analyzeSubset(r *resource.Instance, ctx analys
 					for _, destination := range destinations {
 						if destination.Host == route.Destination.Host {
 							m := msg.NewIngressRouteRulesNotAffected(r, virtualservice.String(), r.Metadata.FullName.String())
+							if other := ctx.Find(collections.IstioNetworkingV1Alpha3Virtualservices.Name(), virtualservice); other != nil {
+								m.Related = append(m.Related, other)
+							}
 
 							key := fmt.Sprintf(util.DestinationHost, http.Name, ruleIndex, routeIndex)
 							if line, ok := util.ErrorLine(r, key); ok {
@@ -20,6 +20,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -40,6 +41,9 @@ func (d *DestinationRuleAnalyzer) Metadata() analysis.Metadata {
 			collections.IstioNetworkingV1Alpha3Virtualservices.Name(),
 			collections.IstioNetworkingV1Alpha3Destinationrules.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.ReferencedResourceNotFound,
+		},
 	}
 }
 
@@ -47,15 +51,16 @@ func (d *DestinationRuleAnalyzer) Metadata() analysis.Metadata {
 func (d *DestinationRuleAnalyzer) Analyze(ctx analysis.Context) {
 	// To avoid repeated iteration, precompute the set of existing destination host+subset combinations
 	destHostsAndSubsets := initDestHostsAndSubsets(ctx)
+	destRulesByHost := initDestRulesByHost(ctx)
 
 	ctx.ForEach(collections.IstioNetworkingV1Alpha3Virtualservices.Name(), func(r *resource.Instance) bool {
-		d.analyzeVirtualService(r, ctx, destHostsAndSubsets)
+		d.analyzeVirtualService(r, ctx, destHostsAndSubsets, destRulesByHost)
 		return true
 	})
 }
 
 func (d *DestinationRuleAnalyzer) analyzeVirtualService(r *resource.Instance, ctx analysis.Context,
-	destHostsAndSubsets map[hostAndSubset]bool) {
+	destHostsAndSubsets map[hostAndSubset]bool, destRulesByHost map[resource.FullName][]*resource.Instance) {
 	vs := r.Message.(*v1alpha3.VirtualService)
 	ns := r.Metadata.FullName.Namespace
 
@@ -64,6 +69,8 @@ func (d *DestinationRuleAnalyzer) analyzeVirtualService(r *resource.Instance, ct
 
 			m := msg.NewReferencedResourceNotFound(r, "host+subset in destinationrule",
 				fmt.Sprintf("%s+%s", ad.Destination.GetHost(), ad.Destination.GetSubset()))
+			// The destination rules for the host exist, but lack the subset
+			m.Related = append(m.Related, destRulesByHost[util.GetResourceNameFromHost(ns, ad.Destination.GetHost())]...)
 
 			key := fmt.Sprintf(util.DestinationHost, ad.RouteRule, ad.ServiceIndex, ad.DestinationIndex)
 			if line, ok := util.ErrorLine(r, key); ok {
@@ -79,6 +86,7 @@ func (d *DestinationRuleAnalyzer) analyzeVirtualService(r *resource.Instance, ct
 
 			m := msg.NewReferencedResourceNotFound(r, "mirror+subset in destinationrule",
 				fmt.Sprintf("%s+%s", ad.Destination.GetHost(), ad.Destination.GetSubset()))
+			m.Related = append(m.Related, destRulesByHost[util.GetResourceNameFromHost(ns, ad.Destination.GetHost())]...)
 
 			key := fmt.Sprintf(util.MirrorHost, ad.ServiceIndex)
 			if line, ok := util.ErrorLine(r, key); ok {
@@ -128,3 +136,15 @@ func initDestHostsAndSubsets(ctx analysis.Context) map[hostAndSubset]bool {
 	})
 	return hostsAndSubsets
 }
+
+// initDestRulesByHost returns the destination rules for each host, to relate messages about a host to them
+func initDestRulesByHost(ctx analysis.Context) map[resource.FullName][]*resource.Instance {
+	rules := make(map[resource.FullName][]*resource.Instance)
+	ctx.ForEach(collections.IstioNetworkingV1Alpha3Destinationrules.Name(), func(r *resource.Instance) bool {
+		dr := r.Message.(*v1alpha3.DestinationRule)
+		host := util.GetResourceNameFromHost(r.Metadata.FullName.Namespace, dr.GetHost())
+		rules[host] = append(rules[host], r)
+		return true
+	})
+	return rules
+}
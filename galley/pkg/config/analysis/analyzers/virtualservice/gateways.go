@@ -20,6 +20,7 @@ import (
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/resource"
@@ -41,6 +42,10 @@ func (s *GatewayAnalyzer) Metadata() analysis.Metadata {
 			collections.IstioNetworkingV1Alpha3Gateways.Name(),
 			collections.IstioNetworkingV1Alpha3Virtualservices.Name(),
 		},
+		Messages: []*diag.MessageType{
+			msg.ReferencedResourceNotFound,
+			msg.VirtualServiceHostNotFoundInGateway,
+		},
 	}
 }
 
@@ -77,6 +82,9 @@ func (s *GatewayAnalyzer) analyzeVirtualService(r *resource.Instance, c analysis
 
 		if !vsHostInGateway(c, gwFullName, vs.Hosts) {
 			m := msg.NewVirtualServiceHostNotFoundInGateway(r, vs.Hosts, vsName.String(), gwFullName.String())
+			if gw := c.Find(collections.IstioNetworkingV1Alpha3Gateways.Name(), gwFullName); gw != nil {
+				m.Related = append(m.Related, gw)
+			}
 
 			if line, ok := util.ErrorLine(r, fmt.Sprintf(util.VSGateway, i)); ok {
 				m.Line = line
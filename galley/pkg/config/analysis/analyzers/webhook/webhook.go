@@ -24,6 +24,7 @@ import (
 
 	"istio.io/api/label"
 	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/istio/pilot/pkg/util/sets"
 	"istio.io/istio/pkg/config/resource"
@@ -50,6 +51,9 @@ func (a *Analyzer) Metadata() analysis.Metadata {
 			webhookCol,
 			serviceCol,
 		},
+		Messages: []*diag.MessageType{
+			msg.InvalidWebhook,
+		},
 	}
 }
 
@@ -0,0 +1,96 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// asciiFolds maps non-ASCII characters to ASCII approximations, covering the accented Latin letters and typographic
+// punctuation that are most likely to appear in configuration.
+var asciiFolds = func() map[rune]string {
+	folds := make(map[rune]string)
+	for _, f := range []struct{ from, to string }{
+		{"ÀÁÂÃÄÅĀĂĄ", "A"}, {"àáâãäåāăą", "a"},
+		{"ÇĆĈĊČ", "C"}, {"çćĉċč", "c"},
+		{"ĎĐÐ", "D"}, {"ďđð", "d"},
+		{"ÈÉÊËĒĔĖĘĚ", "E"}, {"èéêëēĕėęě", "e"},
+		{"ĜĞĠĢ", "G"}, {"ĝğġģ", "g"},
+		{"ĤĦ", "H"}, {"ĥħ", "h"},
+		{"ÌÍÎÏĨĪĬĮİ", "I"}, {"ìíîïĩīĭįı", "i"},
+		{"Ĵ", "J"}, {"ĵ", "j"},
+		{"Ķ", "K"}, {"ķ", "k"},
+		{"ĹĻĽĿŁ", "L"}, {"ĺļľŀł", "l"},
+		{"ÑŃŅŇ", "N"}, {"ñńņň", "n"},
+		{"ÒÓÔÕÖØŌŎŐ", "O"}, {"òóôõöøōŏő", "o"},
+		{"ŔŖŘ", "R"}, {"ŕŗř", "r"},
+		{"ŚŜŞŠ", "S"}, {"śŝşš", "s"},
+		{"ŢŤŦ", "T"}, {"ţťŧ", "t"},
+		{"ÙÚÛÜŨŪŬŮŰŲ", "U"}, {"ùúûüũūŭůűų", "u"},
+		{"Ŵ", "W"}, {"ŵ", "w"},
+		{"ÝŶŸ", "Y"}, {"ýÿŷ", "y"},
+		{"ŹŻŽ", "Z"}, {"źżž", "z"},
+		{"Æ", "AE"}, {"æ", "ae"}, {"Œ", "OE"}, {"œ", "oe"}, {"Þ", "Th"}, {"þ", "th"}, {"ß", "ss"},
+		{"‘’‚‛′", "'"}, {"“”„‟″«»", `"`}, {"‐‑‒–—―−", "-"}, {"…", "..."}, {"•·", "*"}, {"×", "x"},
+		{"\u00a0\u2002\u2003\u2009\u202f", " "}, // non-breaking and fixed-width spaces
+	} {
+		for _, r := range f.from {
+			folds[r] = f.to
+		}
+	}
+	return folds
+}()
+
+// ASCIIFold returns s with only ASCII characters, for consumers that can't handle anything else. Accented Latin letters
+// and typographic punctuation are replaced by their closest ASCII equivalents, e.g. "é" by "e" and "–" by "-". Any other
+// non-ASCII character is escaped as in JSON, e.g. "例" as "\u4f8b", with characters outside of the Basic Multilingual
+// Plane escaped as a UTF-16 surrogate pair, so that JSON strings still decode to the original characters. Invalid UTF-8
+// is escaped as "\ufffd".
+//
+// Folding can change the meaning of serialized output, e.g. typographic quotes become the quotes delimiting JSON
+// strings, so fold the strings of structured output before serializing it, e.g. with ASCIIApproximate.
+func ASCIIFold(s string) string {
+	return foldASCII(s, true)
+}
+
+// ASCIIApproximate replaces the characters of s that have ASCII equivalents, as ASCIIFold does, but keeps any other
+// characters. It is meant for the strings of structured output before it is serialized as JSON: ASCIIFold can then
+// escape the remaining characters of the serialized output, which can only appear inside strings.
+func ASCIIApproximate(s string) string {
+	return foldASCII(s, false)
+}
+
+func foldASCII(s string, escape bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case asciiFolds[r] != "":
+			b.WriteString(asciiFolds[r])
+		case !escape:
+			b.WriteRune(r)
+		case r > 0xffff:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&b, `\u%04x\u%04x`, r1, r2)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestASCIIFold(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ASCIIFold("plain ascii")).To(Equal("plain ascii"))
+	g.Expect(ASCIIFold("café.straße.example.com")).To(Equal("cafe.strasse.example.com"))
+	g.Expect(ASCIIFold("“quoted” – isn’t it…")).To(Equal(`"quoted" - isn't it...`))
+	g.Expect(ASCIIFold("例子")).To(Equal(`\u4f8b\u5b50`))
+	g.Expect(ASCIIFold("🙂")).To(Equal(`\ud83d\ude42`))
+	g.Expect(ASCIIFold("bad\xffbyte")).To(Equal(`bad\ufffdbyte`))
+}
+
+func TestASCIIFold_JSON(t *testing.T) {
+	g := NewWithT(t)
+
+	// Characters that aren't folded are escaped as in JSON, so they survive in JSON strings
+	out, err := json.Marshal("例子 🙂")
+	g.Expect(err).To(BeNil())
+
+	var decoded string
+	g.Expect(json.Unmarshal([]byte(ASCIIFold(string(out))), &decoded)).To(Succeed())
+	g.Expect(decoded).To(Equal("例子 🙂"))
+}
+
+func TestASCIIApproximate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ASCIIApproximate("“quoted” café")).To(Equal(`"quoted" cafe`))
+	g.Expect(ASCIIApproximate("例子 🙂")).To(Equal("例子 🙂"))
+
+	// Approximating before serializing keeps typographic quotes from ending JSON strings
+	out, err := json.Marshal(ASCIIApproximate("“例”"))
+	g.Expect(err).To(BeNil())
+
+	var decoded string
+	g.Expect(json.Unmarshal([]byte(ASCIIFold(string(out))), &decoded)).To(Succeed())
+	g.Expect(decoded).To(Equal(`"例"`))
+}
@@ -0,0 +1,79 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Colors of badges, as understood by badge generators such as shields.io
+const (
+	BadgeRed    = "red"
+	BadgeYellow = "yellow"
+	BadgeGreen  = "green"
+)
+
+// Badge is a status badge summarizing a collection of messages, e.g. "analysis | 3 errors" in red. Its JSON form has
+// the fields of a shields.io endpoint badge, other than the schema version.
+type Badge struct {
+	// Label is the left hand side of the badge, which is always "analysis"
+	Label string `json:"label"`
+
+	// Message is the right hand side of the badge, counting the messages at the worst level present, e.g. "3 errors",
+	// or "passing" if there are no messages
+	Message string `json:"message"`
+
+	// Color is BadgeRed if there are any errors, BadgeYellow if there are any warnings, and BadgeGreen otherwise
+	Color string `json:"color"`
+}
+
+// Badge returns a status badge summarizing the messages.
+func (ms *Messages) Badge() Badge {
+	b := Badge{Label: "analysis", Message: "passing", Color: BadgeGreen}
+	worst, ok := ms.WorstLevel()
+	if !ok {
+		return b
+	}
+
+	b.Message = levelCount(worst, ms.CountsByLevel()[worst])
+	switch worst {
+	case Error:
+		b.Color = BadgeRed
+	case Warning:
+		b.Color = BadgeYellow
+	}
+	return b
+}
+
+// levelCount formats a count of messages at a level, e.g. "3 errors", "1 warning" or "2 info"
+func levelCount(l Level, n int) string {
+	switch l {
+	case Error:
+		return plural(n, "error")
+	case Warning:
+		return plural(n, "warning")
+	default:
+		return fmt.Sprintf("%d %s", n, strings.ToLower(l.String()))
+	}
+}
+
+// plural formats a count of things, e.g. "1 error" or "3 errors"
+func plural(n int, thing string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", thing)
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
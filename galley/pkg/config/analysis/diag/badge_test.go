@@ -0,0 +1,52 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessages_Badge(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	it := NewMessageType(Info, "D1", "Template: %q")
+
+	cases := []struct {
+		msgs     Messages
+		expected Badge
+	}{
+		{nil, Badge{Label: "analysis", Message: "passing", Color: BadgeGreen}},
+		{Messages{NewMessage(it, nil, "a"), NewMessage(it, nil, "b")}, Badge{Label: "analysis", Message: "2 info", Color: BadgeGreen}},
+		{Messages{NewMessage(wt, nil, "a"), NewMessage(it, nil, "b")}, Badge{Label: "analysis", Message: "1 warning", Color: BadgeYellow}},
+		{Messages{NewMessage(wt, nil, "a"), NewMessage(wt, nil, "b")}, Badge{Label: "analysis", Message: "2 warnings", Color: BadgeYellow}},
+		{
+			Messages{NewMessage(et, nil, "a"), NewMessage(wt, nil, "b"), NewMessage(et, nil, "c"), NewMessage(et, nil, "d")},
+			Badge{Label: "analysis", Message: "3 errors", Color: BadgeRed},
+		},
+	}
+	for _, c := range cases {
+		g.Expect(c.msgs.Badge()).To(Equal(c.expected))
+	}
+
+	msgs := Messages{NewMessage(et, nil, "a")}
+	b, err := json.Marshal(msgs.Badge())
+	g.Expect(err).To(BeNil())
+	g.Expect(string(b)).To(Equal(`{"label":"analysis","message":"1 error","color":"red"}`))
+}
@@ -0,0 +1,102 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Formats for rendering a catalog of message types
+const (
+	CatalogTableFormat = "table"
+	CatalogJSONFormat  = "json"
+	CatalogYAMLFormat  = "yaml"
+)
+
+// CatalogFilter selects message types from a catalog. Unset fields match all message types.
+type CatalogFilter struct {
+	// MinLevel, if set, only keeps message types at or above this level
+	MinLevel *Level
+
+	// Category, if set, only keeps message types in this category
+	Category string
+}
+
+// Apply returns the message types matching the filter, preserving their order.
+func (f CatalogFilter) Apply(types []*MessageType) []*MessageType {
+	var result []*MessageType
+	for _, mt := range types {
+		if f.MinLevel != nil && !mt.Level().IsWorseThanOrEqualTo(*f.MinLevel) {
+			continue
+		}
+		if f.Category != "" && mt.Category() != f.Category {
+			continue
+		}
+		result = append(result, mt)
+	}
+	return result
+}
+
+type catalogEntry struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Level       string `json:"level"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description"`
+	Autofix     bool   `json:"autofix,omitempty"`
+}
+
+// PrintCatalog renders a catalog of message types, in the order given, as an aligned table, JSON or YAML.
+func PrintCatalog(types []*MessageType, format string) (string, error) {
+	entries := make([]catalogEntry, 0, len(types))
+	for _, mt := range types {
+		entries = append(entries, catalogEntry{
+			Code:        mt.Code(),
+			Name:        mt.Name(),
+			Level:       mt.Level().String(),
+			Category:    mt.Category(),
+			Description: mt.Description(),
+			Autofix:     mt.HasAutofix(),
+		})
+	}
+
+	switch format {
+	case CatalogJSONFormat:
+		out, err := json.MarshalIndent(entries, "", "\t")
+		return string(out), err
+	case CatalogYAMLFormat:
+		out, err := yaml.Marshal(entries)
+		return strings.TrimSuffix(string(out), "\n"), err
+	case CatalogTableFormat:
+		var b strings.Builder
+		w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tNAME\tLEVEL\tCATEGORY\tDESCRIPTION")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Code, e.Name, e.Level, e.Category, e.Description)
+		}
+		if err := w.Flush(); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	default:
+		return "", fmt.Errorf("invalid catalog format, expected one of %v but got %q",
+			[]string{CatalogTableFormat, CatalogJSONFormat, CatalogYAMLFormat}, format)
+	}
+}
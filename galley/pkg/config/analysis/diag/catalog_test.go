@@ -0,0 +1,83 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCatalog() []*MessageType {
+	return []*MessageType{
+		NewMessageType(Error, "IST0001", "Internal error: %v",
+			WithName("InternalError"), WithCategory("Internal"), WithDescription("Something broke.")),
+		NewMessageType(Info, "IST0101", "Cheese type not found: %q",
+			WithName("CheeseNotFound"), WithCategory("Analysis"), WithDescription("The cheese is missing.")),
+		NewMessageType(Warning, "IST0102", "Cracker type not found: %q",
+			WithName("CrackerNotFound"), WithCategory("Analysis")),
+	}
+}
+
+func TestCatalogFilter(t *testing.T) {
+	g := NewWithT(t)
+
+	types := testCatalog()
+	g.Expect(CatalogFilter{}.Apply(types)).To(Equal(types))
+	g.Expect(CatalogFilter{MinLevel: &Warning}.Apply(types)).To(Equal([]*MessageType{types[0], types[2]}))
+	g.Expect(CatalogFilter{Category: "Analysis"}.Apply(types)).To(Equal([]*MessageType{types[1], types[2]}))
+	g.Expect(CatalogFilter{MinLevel: &Error, Category: "Analysis"}.Apply(types)).To(BeEmpty())
+}
+
+func TestPrintCatalog(t *testing.T) {
+	g := NewWithT(t)
+
+	output, err := PrintCatalog(testCatalog(), CatalogTableFormat)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"CODE     NAME             LEVEL    CATEGORY  DESCRIPTION\n" +
+			"IST0001  InternalError    Error    Internal  Something broke.\n" +
+			"IST0101  CheeseNotFound   Info     Analysis  The cheese is missing.\n" +
+			"IST0102  CrackerNotFound  Warning  Analysis  ",
+	))
+
+	output, err = PrintCatalog(testCatalog()[:1], CatalogJSONFormat)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`[
+	{
+		"code": "IST0001",
+		"name": "InternalError",
+		"level": "Error",
+		"category": "Internal",
+		"description": "Something broke."
+	}
+]`))
+
+	output, err = PrintCatalog(testCatalog()[:1], CatalogYAMLFormat)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`- category: Internal
+  code: IST0001
+  description: Something broke.
+  level: Error
+  name: InternalError`))
+
+	output, err = PrintCatalog([]*MessageType{NewMessageType(Error, "IST0042", "Cheese type not found: %q",
+		WithName("CheeseNotFound"), WithAutofix())}, CatalogJSONFormat)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring(`"autofix": true`))
+
+	_, err = PrintCatalog(testCatalog(), "bogus")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,58 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"strings"
+)
+
+// compactPlaceholder stands in for unknown fields in the compact format, keeping the columns fixed.
+const compactPlaceholder = "-"
+
+// CompactFormatter renders each message on a single line of tab separated fields, suitable for ingestion by log
+// processing systems:
+//
+//	LEVEL	CODE	namespace/kind/name	message
+//
+// Unknown namespace, kind and name fields are replaced with "-". Any line breaks or tabs in the message text are
+// replaced with spaces. Messages are rendered in the order given.
+type CompactFormatter struct{}
+
+var _ Formatter = CompactFormatter{}
+
+var compactSanitizer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// Format implements Formatter
+func (f CompactFormatter) Format(ms Messages) (string, error) {
+	lines := make([]string, 0, len(ms))
+	for i := range ms {
+		m := &ms[i]
+		namespace, kind, name := m.resourceCoordinates()
+		lines = append(lines, strings.Join([]string{
+			strings.ToUpper(m.Type.Level().String()),
+			m.Type.Code(),
+			compactField(namespace) + "/" + compactField(kind) + "/" + compactField(name),
+			compactSanitizer.Replace(m.Text()),
+		}, "\t"))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func compactField(s string) string {
+	if s == "" {
+		return compactPlaceholder
+	}
+	return s
+}
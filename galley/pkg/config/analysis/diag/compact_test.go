@@ -0,0 +1,42 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCompactFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	wt := NewMessageType(Warning, "IST0043", "Multi-line\n\tcheese: %s")
+
+	msgs := Messages{
+		NewMessage(mt, mockSchemaResource("prod", "reviews"), "Feta"),
+		NewMessage(wt, MockResource("toppings"), "Brie"),
+		NewMessage(mt, nil, "Gouda"),
+	}
+
+	output, err := CompactFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"ERROR\tIST0042\tprod/VirtualService/reviews\tCheese type not found: \"Feta\"\n" +
+			"WARNING\tIST0043\tdefault/-/toppings\tMulti-line  cheese: Brie\n" +
+			"ERROR\tIST0042\t-/-/-\tCheese type not found: \"Gouda\"",
+	))
+}
@@ -0,0 +1,50 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+// Confidence is how certain an analyzer is that a message reports a real problem. Heuristic analyzers may set it on
+// the messages they report, so that users can hide those that are less certain, e.g. when gating changes on analysis.
+// The zero Confidence means the analyzer didn't say, and ranks as HighConfidence.
+type Confidence struct {
+	rank int
+	name string
+}
+
+var (
+	// LowConfidence is for messages that are more likely than not to be false positives
+	LowConfidence = Confidence{1, "Low"}
+
+	// MediumConfidence is for messages that are probably, but not certainly, real problems
+	MediumConfidence = Confidence{2, "Medium"}
+
+	// HighConfidence is for messages that are almost certainly real problems
+	HighConfidence = Confidence{3, "High"}
+)
+
+func (c Confidence) String() string {
+	return c.name
+}
+
+// IsAtLeast returns whether the confidence is at least as high as the target.
+func (c Confidence) IsAtLeast(target Confidence) bool {
+	return c.effective().rank >= target.effective().rank
+}
+
+func (c Confidence) effective() Confidence {
+	if c == (Confidence{}) {
+		return HighConfidence
+	}
+	return c
+}
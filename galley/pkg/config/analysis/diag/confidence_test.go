@@ -0,0 +1,34 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestConfidence_IsAtLeast(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(HighConfidence.IsAtLeast(MediumConfidence)).To(BeTrue())
+	g.Expect(MediumConfidence.IsAtLeast(MediumConfidence)).To(BeTrue())
+	g.Expect(LowConfidence.IsAtLeast(MediumConfidence)).To(BeFalse())
+
+	// Unset confidence ranks as high
+	g.Expect(Confidence{}.IsAtLeast(HighConfidence)).To(BeTrue())
+	g.Expect(LowConfidence.IsAtLeast(Confidence{})).To(BeFalse())
+	g.Expect(Confidence{}.String()).To(BeEmpty())
+}
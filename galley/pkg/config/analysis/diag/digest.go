@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	digestClusterScoped = "Cluster-scoped resources"
+	digestNoResource    = "No resource"
+)
+
+// DigestFormatter renders messages as a plain text digest meant to be read in a proportional font, e.g. as the body
+// of an email. It starts with the number of messages at each level, followed by the messages grouped under a heading
+// for each namespace. Namespaces are sorted by name and followed by the messages on cluster-scoped resources, then
+// those without a resource. Within each group, messages follow the order of Sort, and each is prefixed with its level.
+type DigestFormatter struct{}
+
+var _ Formatter = DigestFormatter{}
+
+// Format implements Formatter
+func (f DigestFormatter) Format(ms Messages) (string, error) {
+	if len(ms) == 0 {
+		return "Analysis found no issues.", nil
+	}
+
+	sorted := append(ms[:0:0], ms...)
+	sorted.Sort()
+
+	var namespaces []string
+	groups := make(map[string][]string)
+	for i := range sorted {
+		m := &sorted[i]
+		heading := digestNoResource
+		line := fmt.Sprintf("%v [%v] %s", m.Type.Level(), m.Type.Code(), m.Text())
+		if m.Resource != nil {
+			namespace, kind, name := m.resourceCoordinates()
+			heading = digestClusterScoped
+			if namespace != "" {
+				heading = "Namespace " + namespace
+			}
+			if kind != "" {
+				name = kind + " " + name
+			}
+			line = fmt.Sprintf("%v [%v] %s: %s", m.Type.Level(), m.Type.Code(), name, m.Text())
+
+			if _, ok := groups[heading]; !ok && namespace != "" {
+				namespaces = append(namespaces, heading)
+			}
+		}
+		groups[heading] = append(groups[heading], line)
+	}
+	sort.Strings(namespaces)
+
+	sections := []string{fmt.Sprintf("Analysis found %d issue(s): %s.", len(ms), ms.levelCounts())}
+	for _, heading := range append(namespaces, digestClusterScoped, digestNoResource) {
+		if lines, ok := groups[heading]; ok {
+			sections = append(sections, heading+"\n"+strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
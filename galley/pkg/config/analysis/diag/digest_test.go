@@ -0,0 +1,63 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/config/resource"
+)
+
+func TestDigestFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	clusterScoped := &resource.Instance{
+		Metadata: resource.Metadata{FullName: resource.NewShortOrFullName("", "mesh-config")},
+		Origin:   testOrigin{name: "mesh-config"},
+	}
+
+	msgs := Messages{
+		NewMessage(wt, mockSchemaResource("staging", "details"), "w"),
+		NewMessage(wt, nil, "orphan"),
+		NewMessage(mt, clusterScoped, "mesh"),
+		NewMessage(wt, mockSchemaResource("prod", "reviews"), "w"),
+		NewMessage(mt, mockSchemaResource("prod", "reviews"), "e"),
+	}
+
+	out, err := DigestFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(Equal(`Analysis found 5 issue(s): 2 Error, 3 Warning, 0 Info.
+
+Namespace prod
+Error [B1] VirtualService reviews: Template: "e"
+Warning [C1] VirtualService reviews: Template: "w"
+
+Namespace staging
+Warning [C1] VirtualService details: Template: "w"
+
+Cluster-scoped resources
+Error [B1] mesh-config: Template: "mesh"
+
+No resource
+Warning [C1] Template: "orphan"`))
+
+	out, err = DigestFormatter{}.Format(nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(Equal("Analysis found no issues."))
+}
@@ -0,0 +1,69 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxEventMessageLength is the maximum length of the message of a Kubernetes Event, beyond which it is truncated
+const maxEventMessageLength = 1024
+
+// KubernetesEvent returns a Kubernetes Event reporting the message on its resource, e.g. for a controller to surface
+// analysis results with the native tooling. The reason is the code of the message and the type is Warning, unless the
+// message is an Info message, in which case the type is Normal. The text of the message is truncated to the length
+// Kubernetes allows. It returns nil if the message has no resource or its type isn't known.
+//
+// Creating the Event is left to the caller, who should also set its source and timestamps.
+func (m *Message) KubernetesEvent() *corev1.Event {
+	if m.Resource == nil || m.Resource.Metadata.Schema == nil {
+		return nil
+	}
+	namespace, kind, name := m.resourceCoordinates()
+
+	eventType := corev1.EventTypeWarning
+	if m.Type.Level() == Info {
+		eventType = corev1.EventTypeNormal
+	}
+	// The API version of core resources has no group
+	s := m.Resource.Metadata.Schema
+	apiVersion := s.Version()
+	if s.Group() != "" {
+		apiVersion = s.Group() + "/" + apiVersion
+	}
+	// Events live in the namespace of the object they are about, which for cluster-scoped objects is the default one
+	eventNamespace := namespace
+	if eventNamespace == "" {
+		eventNamespace = metav1.NamespaceDefault
+	}
+
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: name + ".",
+			Namespace:    eventNamespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      apiVersion,
+			Kind:            kind,
+			Namespace:       namespace,
+			Name:            name,
+			ResourceVersion: string(m.Resource.Metadata.Version),
+		},
+		Reason:  m.Type.Code(),
+		Message: Truncate(m.Text(), maxEventMessageLength),
+		Type:    eventType,
+	}
+}
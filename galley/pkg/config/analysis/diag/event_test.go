@@ -0,0 +1,69 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+func TestMessage_KubernetesEvent(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	r := mockSchemaResource("prod", "reviews")
+	r.Metadata.Version = "42"
+
+	m := NewMessage(mt, r, "Feta")
+	e := m.KubernetesEvent()
+	g.Expect(e.GenerateName).To(Equal("reviews."))
+	g.Expect(e.Namespace).To(Equal("prod"))
+	g.Expect(e.InvolvedObject).To(Equal(corev1.ObjectReference{
+		APIVersion:      "networking.istio.io/v1alpha3",
+		Kind:            "VirtualService",
+		Namespace:       "prod",
+		Name:            "reviews",
+		ResourceVersion: "42",
+	}))
+	g.Expect(e.Reason).To(Equal("IST0042"))
+	g.Expect(e.Message).To(Equal(`Cheese type not found: "Feta"`))
+	g.Expect(e.Type).To(Equal(corev1.EventTypeWarning))
+
+	m = NewMessage(NewMessageType(Info, "IST0043", "Cheese type not found: %q"), r, strings.Repeat("a", 2000))
+	e = m.KubernetesEvent()
+	g.Expect(e.Type).To(Equal(corev1.EventTypeNormal))
+	g.Expect(e.Message).To(HaveLen(maxEventMessageLength))
+
+	// Events about cluster-scoped objects go in the default namespace
+	node := MockResource("worker-1")
+	node.Metadata.FullName = resource.NewShortOrFullName("", "worker-1")
+	node.Metadata.Schema = collections.K8SCoreV1Nodes.Resource()
+	m = NewMessage(mt, node, "a")
+	e = m.KubernetesEvent()
+	g.Expect(e.Namespace).To(Equal("default"))
+	g.Expect(e.InvolvedObject.Namespace).To(BeEmpty())
+	g.Expect(e.InvolvedObject.APIVersion).To(Equal("v1"))
+
+	m = NewMessage(mt, MockResource("unknown"), "a")
+	g.Expect(m.KubernetesEvent()).To(BeNil())
+	m = NewMessage(mt, nil, "a")
+	g.Expect(m.KubernetesEvent()).To(BeNil())
+}
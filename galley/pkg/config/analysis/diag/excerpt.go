@@ -0,0 +1,78 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position returns the file and line the message refers to, if the message is on a resource loaded from a file. The
+// line is zero if it isn't known.
+func (m *Message) Position() (string, int) {
+	path := sourceFile(m.Resource)
+	if path == "" {
+		return "", 0
+	}
+	_, line := splitReference(m.Resource.Origin.Reference().String())
+	if m.Line != 0 {
+		line = m.Line
+	}
+	return path, line
+}
+
+// Excerpt returns the lines of content around the given 1-based line, with up to context lines either side, in the
+// style of a compiler error:
+//
+//	  10 | spec:
+//	> 11 |   hosts:
+//	     |   ^
+//	  12 |   - reviews
+//
+// The caret marks the first non-blank character of the line, since origins don't record a column. It returns empty if
+// the line is outside of the content.
+func Excerpt(content []byte, line, context int) string {
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+	first := line - context
+	if first < 1 {
+		first = 1
+	}
+	last := line + context
+	if last > len(lines) {
+		last = len(lines)
+	}
+
+	width := len(fmt.Sprint(last))
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		text := strings.TrimRight(lines[n-1], "\r")
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %*d | %s\n", marker, width, n, text)
+		if n == line {
+			column := len(text) - len(strings.TrimLeft(text, " \t"))
+			fmt.Fprintf(&b, "  %s | %s^\n", strings.Repeat(" ", width), text[:column])
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
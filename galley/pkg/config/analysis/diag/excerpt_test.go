@@ -0,0 +1,79 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/config/resource"
+)
+
+const excerptContent = `apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: reviews
+spec:
+  hosts:
+  - reviews
+`
+
+func TestExcerpt(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(Excerpt([]byte(excerptContent), 6, 1)).To(Equal(
+		"  5 | spec:\n" +
+			"> 6 |   hosts:\n" +
+			"    |   ^\n" +
+			"  7 |   - reviews",
+	))
+
+	// Context is clipped to the content, and line numbers are aligned
+	content := excerptContent + "\n\n\n  http:\n"
+	g.Expect(Excerpt([]byte(content), 11, 2)).To(Equal(
+		"   9 | \n" +
+			"  10 | \n" +
+			"> 11 |   http:\n" +
+			"     |   ^",
+	))
+	g.Expect(Excerpt([]byte(excerptContent), 1, 0)).To(Equal(
+		"> 1 | apiVersion: networking.istio.io/v1alpha3\n" +
+			"    | ^",
+	))
+
+	g.Expect(Excerpt([]byte(excerptContent), 0, 1)).To(BeEmpty())
+	g.Expect(Excerpt([]byte(excerptContent), 8, 1)).To(BeEmpty())
+}
+
+func TestMessage_Position(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	m := NewMessage(mt, &resource.Instance{Origin: testOrigin{name: "toppings", ref: testReference{"path/to/file:12"}}}, "Feta")
+
+	path, line := m.Position()
+	g.Expect(path).To(Equal("path/to/file"))
+	g.Expect(line).To(Equal(12))
+
+	m.Line = 14
+	_, line = m.Position()
+	g.Expect(line).To(Equal(14))
+
+	m = NewMessage(mt, MockResource("toppings"), "Feta")
+	path, line = m.Position()
+	g.Expect(path).To(BeEmpty())
+	g.Expect(line).To(BeZero())
+}
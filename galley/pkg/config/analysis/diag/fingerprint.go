@@ -0,0 +1,203 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns a stable identifier for the finding represented by the message. Two messages have the same
+// fingerprint if they have the same code, the same resource (as identified by its origin's Comparator) and the same
+// parameter values. The level, template text, line, doc ref and related resources of the message do not participate,
+// so the fingerprint is unaffected by rewording a template or by changes in file layout.
+//
+// Parameters are encoded as formatted by %v, except those whose types hold pointers and that are neither errors nor
+// fmt.Stringers: %v may format them as addresses, which differ between runs, so they are encoded as JSON instead,
+// following the pointers. Fingerprints therefore stay the same across runs, e.g. for baselines of known findings.
+func (m *Message) Fingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", m.Type.Code())
+	if m.Resource != nil && m.Resource.Origin != nil {
+		fmt.Fprintf(h, "%s", m.Resource.Origin.Comparator())
+	}
+	for _, p := range m.Parameters {
+		_, _ = io.WriteString(h, "\x00")
+		writeParameter(h, p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeParameter writes a stable encoding of a parameter for Fingerprint.
+func writeParameter(w io.Writer, p interface{}) {
+	switch p.(type) {
+	case error, fmt.Stringer:
+		// Formatted with their own methods, which are expected to describe the value rather than its address
+	default:
+		if p != nil && holdsPointers(reflect.TypeOf(p)) {
+			if b, err := json.Marshal(p); err == nil {
+				_, _ = w.Write(b)
+				return
+			}
+		}
+	}
+	fmt.Fprintf(w, "%v", p)
+}
+
+// holdsPointers returns whether values of the type may refer to other values, so that formatting them with %v may
+// include addresses. Interfaces are assumed to.
+func holdsPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array, reflect.Slice:
+		return holdsPointers(t.Elem())
+	case reflect.Map:
+		return holdsPointers(t.Key()) || holdsPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if holdsPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Equal returns true if both collections contain the same findings, compared by Fingerprint. The order of the
+// messages is not significant, but the number of times each finding occurs is.
+func (ms *Messages) Equal(other Messages) bool {
+	if len(*ms) != len(other) {
+		return false
+	}
+
+	a, b := ms.fingerprints(), other.fingerprints()
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff describes how the actual messages differ from the expected ones, comparing them by Fingerprint as Equal does.
+// It lists the unexpected messages, which are only in actual, then the missing messages, which are only in expected,
+// each in the order of Sort. If a finding occurs more often in one collection than the other, only the extra
+// occurrences are listed. It returns an empty string if the collections are equal, so that tests of analyzers can
+// assert on it to get a readable failure:
+//
+//	g.Expect(diag.Diff(actual, expected)).To(BeEmpty())
+func Diff(actual, expected Messages) string {
+	unexpected, missing := subtract(actual, expected), subtract(expected, actual)
+	if len(unexpected) == 0 && len(missing) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, section := range []struct {
+		heading string
+		prefix  string
+		ms      Messages
+	}{
+		{"Unexpected messages:", "+ ", unexpected},
+		{"Missing messages:", "- ", missing},
+	} {
+		if len(section.ms) == 0 {
+			continue
+		}
+		section.ms.Sort()
+		b.WriteString(section.heading + "\n")
+		for i := range section.ms {
+			b.WriteString(section.prefix + section.ms[i].String() + "\n")
+		}
+	}
+	return b.String()
+}
+
+// subtract returns the messages in ms left over once each message in other has cancelled out one message with the
+// same fingerprint.
+func subtract(ms, other Messages) Messages {
+	counts := make(map[string]int)
+	for i := range other {
+		counts[other[i].Fingerprint()]++
+	}
+
+	var result Messages
+	for i := range ms {
+		fp := ms[i].Fingerprint()
+		if counts[fp] > 0 {
+			counts[fp]--
+			continue
+		}
+		result = append(result, ms[i])
+	}
+	return result
+}
+
+// ContainsFingerprint returns true if any of the messages has the given fingerprint.
+func (ms *Messages) ContainsFingerprint(fp string) bool {
+	for i := range *ms {
+		if (*ms)[i].Fingerprint() == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByFingerprint only keeps messages having one of the given fingerprints, preserving their order.
+func (ms *Messages) FilterByFingerprint(fps ...string) Messages {
+	wanted := make(map[string]bool, len(fps))
+	for _, fp := range fps {
+		wanted[fp] = true
+	}
+
+	outputMessages := Messages{}
+	for i := range *ms {
+		if wanted[(*ms)[i].Fingerprint()] {
+			outputMessages = append(outputMessages, (*ms)[i])
+		}
+	}
+	return outputMessages
+}
+
+// AnyOf returns the messages matching any of the given fingerprints, such as those of issues known to be resolved, so
+// that callers can fail when a resolved issue reappears. It is the opposite of SuppressBaseline, which hides the
+// messages it matches.
+func (ms *Messages) AnyOf(fingerprints []string) Messages {
+	return ms.FilterByFingerprint(fingerprints...)
+}
+
+// NewSince returns the messages in current whose fingerprint doesn't match any message in previous, such as the
+// findings added since an earlier snapshot of the same analysis. Messages are compared by fingerprint only, so the
+// order of either collection doesn't matter. The result keeps the order of current.
+func NewSince(current, previous Messages) Messages {
+	return current.SuppressBaseline(previous.fingerprints()...)
+}
+
+// fingerprints returns the sorted fingerprints of the messages.
+func (ms *Messages) fingerprints() []string {
+	fps := make([]string, 0, len(*ms))
+	for i := range *ms {
+		fps = append(fps, (*ms)[i].Fingerprint())
+	}
+	sort.Strings(fps)
+	return fps
+}
@@ -0,0 +1,167 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage_Fingerprint(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	reworded := NewMessageType(Warning, "B1", "Reworded template: %q")
+
+	m := NewMessage(mt, MockResource("A"), "B")
+	g.Expect(m.Fingerprint()).To(HaveLen(64))
+
+	same := NewMessage(reworded, MockResource("A"), "B")
+	same.Line = 42
+	same.DocRef = "ref"
+	g.Expect(same.Fingerprint()).To(Equal(m.Fingerprint()))
+
+	otherCode := NewMessage(NewMessageType(Error, "B2", "Template: %q"), MockResource("A"), "B")
+	otherResource := NewMessage(mt, MockResource("B"), "B")
+	otherParam := NewMessage(mt, MockResource("A"), "C")
+	noResource := NewMessage(mt, nil, "B")
+	for _, o := range []Message{otherCode, otherResource, otherParam, noResource} {
+		g.Expect(o.Fingerprint()).NotTo(Equal(m.Fingerprint()))
+	}
+}
+
+type fingerprintHost struct {
+	Name string
+	Port *int
+}
+
+func TestMessage_FingerprintPointerParameters(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Host: %v")
+	port := func(p int) *int { return &p }
+
+	// Equal values at different addresses, as in separate runs, have the same fingerprint
+	m := NewMessage(mt, MockResource("A"), &fingerprintHost{Name: "reviews", Port: port(80)})
+	same := NewMessage(mt, MockResource("A"), &fingerprintHost{Name: "reviews", Port: port(80)})
+	g.Expect(same.Fingerprint()).To(Equal(m.Fingerprint()))
+
+	other := NewMessage(mt, MockResource("A"), &fingerprintHost{Name: "reviews", Port: port(8080)})
+	g.Expect(other.Fingerprint()).NotTo(Equal(m.Fingerprint()))
+
+	slices := NewMessage(mt, MockResource("A"), []*fingerprintHost{{Name: "reviews"}})
+	sameSlices := NewMessage(mt, MockResource("A"), []*fingerprintHost{{Name: "reviews"}})
+	g.Expect(sameSlices.Fingerprint()).To(Equal(slices.Fingerprint()))
+}
+
+func TestMessages_Equal(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	reworded := NewMessageType(Error, "B1", "Reworded template: %q")
+
+	first := NewMessage(mt, MockResource("A"), "B")
+	second := NewMessage(mt, MockResource("B"), "B")
+
+	msgs := Messages{first, second}
+	g.Expect(msgs.Equal(Messages{second, first})).To(BeTrue())
+	g.Expect(msgs.Equal(Messages{
+		NewMessage(reworded, MockResource("B"), "B"),
+		NewMessage(reworded, MockResource("A"), "B"),
+	})).To(BeTrue())
+
+	g.Expect(msgs.Equal(Messages{first})).To(BeFalse())
+	g.Expect(msgs.Equal(Messages{first, first})).To(BeFalse())
+	g.Expect(msgs.Equal(Messages{first, NewMessage(mt, MockResource("B"), "C")})).To(BeFalse())
+	g.Expect((&Messages{}).Equal(nil)).To(BeTrue())
+}
+
+func TestDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+
+	first := NewMessage(mt, MockResource("A"), "B")
+	second := NewMessage(wt, MockResource("B"), "B")
+	third := NewMessage(mt, MockResource("C"), "B")
+
+	g.Expect(Diff(Messages{first, second}, Messages{second, first})).To(BeEmpty())
+	g.Expect(Diff(nil, nil)).To(BeEmpty())
+
+	g.Expect(Diff(Messages{second, first, first}, Messages{first, third})).To(Equal(
+		"Unexpected messages:\n" +
+			"+ Error [B1] (A) Template: \"B\"\n" +
+			"+ Warning [C1] (B) Template: \"B\"\n" +
+			"Missing messages:\n" +
+			"- Error [B1] (C) Template: \"B\"\n"))
+	g.Expect(Diff(nil, Messages{first})).To(Equal("Missing messages:\n- Error [B1] (A) Template: \"B\"\n"))
+}
+
+func TestMessages_FilterByFingerprint(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	first := NewMessage(mt, MockResource("A"), "B")
+	second := NewMessage(mt, MockResource("B"), "B")
+	third := NewMessage(mt, MockResource("C"), "B")
+
+	msgs := Messages{first, second, third}
+
+	g.Expect(msgs.ContainsFingerprint(second.Fingerprint())).To(BeTrue())
+	g.Expect(msgs.ContainsFingerprint("nope")).To(BeFalse())
+
+	g.Expect(msgs.FilterByFingerprint(third.Fingerprint(), first.Fingerprint(), "nope")).To(Equal(Messages{first, third}))
+	g.Expect(msgs.FilterByFingerprint()).To(Equal(Messages{}))
+}
+
+func TestMessages_AnyOf(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	fixed := NewMessage(mt, MockResource("A"), "B")
+	open := NewMessage(mt, MockResource("B"), "B")
+
+	resolved := []string{fixed.Fingerprint()}
+
+	// A resolved issue reappearing is surfaced
+	msgs := Messages{open, fixed}
+	g.Expect(msgs.AnyOf(resolved)).To(Equal(Messages{fixed}))
+
+	msgs = Messages{open}
+	g.Expect(msgs.AnyOf(resolved)).To(BeEmpty())
+	g.Expect(msgs.AnyOf(nil)).To(BeEmpty())
+}
+
+func TestNewSince(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	kept := NewMessage(mt, MockResource("A"), "B")
+	fixed := NewMessage(mt, MockResource("B"), "B")
+	added := NewMessage(mt, MockResource("C"), "B")
+	// Differs from kept only in ways that don't affect its fingerprint
+	keptMoved := kept
+	keptMoved.Line = 42
+
+	previous := Messages{fixed, kept}
+	current := Messages{added, keptMoved}
+
+	g.Expect(NewSince(current, previous)).To(Equal(Messages{added}))
+	g.Expect(NewSince(Messages{keptMoved, added}, Messages{kept, fixed})).To(Equal(Messages{added}))
+	g.Expect(NewSince(current, nil)).To(Equal(current))
+	g.Expect(NewSince(nil, previous)).To(BeEmpty())
+}
@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Formatter renders a collection of messages in a particular output format.
+type Formatter interface {
+	Format(ms Messages) (string, error)
+}
+
+// RenderEach renders each message separately with the formatter, returning one string per message in sorted order.
+// This is useful for consumers that report each message on its own, such as in separate review comments.
+func (ms *Messages) RenderEach(f Formatter) ([]string, error) {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+	result := make([]string, 0, len(sorted))
+	for _, m := range sorted {
+		out, err := f.Format(Messages{m})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out)
+	}
+	return result, nil
+}
+
+// formatters holds the formatters available by name, in the order they were registered. The built-in formatters
+// register themselves at init, and other packages may add their own with RegisterFormatter.
+var formatters = struct {
+	sync.RWMutex
+	names  []string
+	byName map[string]Formatter
+}{
+	byName: make(map[string]Formatter),
+}
+
+func init() {
+	for _, f := range []struct {
+		name string
+		f    Formatter
+	}{
+		{"sarif", SARIFFormatter{IncludeHelp: true}},
+		{"tree", TreeFormatter{}},
+		{"compact", CompactFormatter{}},
+		{"junit", JUnitFormatter{}},
+		{"html", HTMLFormatter{}},
+		{"digest", DigestFormatter{}},
+		{"gitlab", GitLabFormatter{}},
+		{"summary", ResourceSummaryFormatter{}},
+	} {
+		if err := RegisterFormatter(f.name, f.f); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterFormatter makes a formatter available by name, e.g. for selection on the command line. It is an error to
+// register a formatter with the name of one already registered.
+func RegisterFormatter(name string, f Formatter) error {
+	formatters.Lock()
+	defer formatters.Unlock()
+
+	if _, ok := formatters.byName[name]; ok {
+		return fmt.Errorf("a formatter named %q is already registered", name)
+	}
+	formatters.byName[name] = f
+	formatters.names = append(formatters.names, name)
+	return nil
+}
+
+// FormatterByName returns the registered formatter with the given name, if any.
+func FormatterByName(name string) (Formatter, bool) {
+	formatters.RLock()
+	defer formatters.RUnlock()
+
+	f, ok := formatters.byName[name]
+	return f, ok
+}
+
+// unregisterFormatter removes the formatter with the given name from the registry, so that tests can undo their
+// registrations.
+func unregisterFormatter(name string) {
+	formatters.Lock()
+	defer formatters.Unlock()
+
+	if _, ok := formatters.byName[name]; !ok {
+		return
+	}
+	delete(formatters.byName, name)
+	for i, n := range formatters.names {
+		if n == name {
+			formatters.names = append(formatters.names[:i], formatters.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// FormatterNames returns the names of all registered formatters, in the order they were registered.
+func FormatterNames() []string {
+	formatters.RLock()
+	defer formatters.RUnlock()
+
+	return append([]string(nil), formatters.names...)
+}
@@ -0,0 +1,80 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type failingFormatter struct{}
+
+func (failingFormatter) Format(Messages) (string, error) {
+	return "", errors.New("cheese overflow")
+}
+
+func TestMessages_RenderEach(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	msgs := Messages{
+		NewMessage(mt, MockResource("toppings"), "Brie"),
+		NewMessage(mt, MockResource("pantry"), "Feta"),
+	}
+
+	out, err := msgs.RenderEach(CompactFormatter{})
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(Equal([]string{
+		"ERROR\tIST0042\tdefault/-/pantry\tCheese type not found: \"Feta\"",
+		"ERROR\tIST0042\tdefault/-/toppings\tCheese type not found: \"Brie\"",
+	}))
+
+	// The input order is left untouched
+	g.Expect(msgs[0].Resource.Origin.FriendlyName()).To(Equal("toppings"))
+
+	empty := Messages{}
+	out, err = empty.RenderEach(CompactFormatter{})
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(BeEmpty())
+
+	_, err = msgs.RenderEach(failingFormatter{})
+	g.Expect(err).To(MatchError("cheese overflow"))
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	g := NewWithT(t)
+	t.Cleanup(func() { unregisterFormatter("ticket") })
+
+	builtins := []string{"sarif", "tree", "compact", "junit", "html", "digest", "gitlab", "summary"}
+	g.Expect(FormatterNames()).To(ContainElements(builtins))
+	g.Expect(FormatterNames()).NotTo(ContainElement("ticket"))
+	f, ok := FormatterByName("compact")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(f).To(Equal(CompactFormatter{}))
+
+	_, ok = FormatterByName("ticket")
+	g.Expect(ok).To(BeFalse())
+
+	g.Expect(RegisterFormatter("ticket", failingFormatter{})).To(Succeed())
+	f, ok = FormatterByName("ticket")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(f).To(Equal(failingFormatter{}))
+	g.Expect(FormatterNames()).To(ContainElements(append(builtins, "ticket")))
+
+	g.Expect(RegisterFormatter("ticket", CompactFormatter{})).To(MatchError(ContainSubstring(`"ticket" is already registered`)))
+	g.Expect(RegisterFormatter("sarif", CompactFormatter{})).NotTo(Succeed())
+}
@@ -0,0 +1,83 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+)
+
+// GitLabFormatter renders messages as a GitLab Code Quality report, for the code quality widget of merge requests.
+// Messages on resources that weren't loaded from a file are located by the name of their resource instead of a path,
+// and those without a resource at all by an empty path. Messages without a line are located at line 1, as GitLab
+// requires one. Messages are rendered in sorted order.
+type GitLabFormatter struct{}
+
+var _ Formatter = GitLabFormatter{}
+
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+type gitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitLabLines `json:"lines"`
+}
+
+type gitLabLines struct {
+	Begin int `json:"begin"`
+}
+
+// Format implements Formatter
+func (f GitLabFormatter) Format(ms Messages) (string, error) {
+	sorted := append(ms[:0:0], ms...)
+	sorted.Sort()
+
+	issues := make([]gitLabIssue, 0, len(sorted))
+	for i := range sorted {
+		m := &sorted[i]
+		path, line := m.Position()
+		if path == "" && m.Resource != nil && m.Resource.Origin != nil {
+			path = m.Resource.Origin.FriendlyName()
+		}
+		if line <= 0 {
+			line = 1
+		}
+		issues = append(issues, gitLabIssue{
+			Description: m.Text(),
+			CheckName:   m.Type.Code(),
+			Fingerprint: m.Fingerprint(),
+			Severity:    gitLabSeverity(m.Type.Level()),
+			Location:    gitLabLocation{Path: path, Lines: gitLabLines{Begin: line}},
+		})
+	}
+
+	out, err := json.MarshalIndent(issues, "", "  ")
+	return string(out), err
+}
+
+func gitLabSeverity(l Level) string {
+	switch l {
+	case Error:
+		return "major"
+	case Warning:
+		return "minor"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/config/resource"
+)
+
+func TestGitLabFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	wt := NewMessageType(Warning, "IST0043", "Cracker type not found: %q")
+	it := NewMessageType(Info, "IST0044", "Wine type not found: %q")
+
+	fromFile := NewMessage(et, &resource.Instance{Origin: testOrigin{name: "toppings/cheese", ref: testReference{"path/to/file:12"}}}, "Feta")
+	fromCluster := NewMessage(wt, MockResource("crackers"), "Saltine")
+	orphan := NewMessage(it, nil, "Merlot")
+
+	output, err := GitLabFormatter{}.Format(Messages{orphan, fromCluster, fromFile})
+	g.Expect(err).To(BeNil())
+
+	var issues []gitLabIssue
+	g.Expect(json.Unmarshal([]byte(output), &issues)).To(Succeed())
+	g.Expect(issues).To(Equal([]gitLabIssue{
+		{
+			Description: `Cheese type not found: "Feta"`,
+			CheckName:   "IST0042",
+			Fingerprint: fromFile.Fingerprint(),
+			Severity:    "major",
+			Location:    gitLabLocation{Path: "path/to/file", Lines: gitLabLines{Begin: 12}},
+		},
+		{
+			Description: `Cracker type not found: "Saltine"`,
+			CheckName:   "IST0043",
+			Fingerprint: fromCluster.Fingerprint(),
+			Severity:    "minor",
+			Location:    gitLabLocation{Path: "crackers", Lines: gitLabLines{Begin: 1}},
+		},
+		{
+			Description: `Wine type not found: "Merlot"`,
+			CheckName:   "IST0044",
+			Fingerprint: orphan.Fingerprint(),
+			Severity:    "info",
+			Location:    gitLabLocation{Path: "", Lines: gitLabLines{Begin: 1}},
+		},
+	}))
+	g.Expect(output).To(ContainSubstring(`"check_name": "IST0042"`))
+}
+
+func TestGitLabFormatter_Empty(t *testing.T) {
+	g := NewWithT(t)
+
+	output, err := GitLabFormatter{}.Format(nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("[]"))
+}
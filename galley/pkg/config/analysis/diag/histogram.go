@@ -0,0 +1,85 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultHistogramWidth = 40
+
+// HistogramFormatter renders the number of messages at each level, or with each code, as a horizontal bar chart.
+// Bars are scaled so that the largest count spans Width columns, and any non-zero count has a bar at least one
+// column wide. All levels are listed, most severe first; codes are listed in order, and only if present.
+type HistogramFormatter struct {
+	// ByCode counts messages per code rather than per level
+	ByCode bool
+
+	// Width is the maximum width of a bar, in columns. Defaults to 40 if not positive.
+	Width int
+}
+
+var _ Formatter = HistogramFormatter{}
+
+// Format implements Formatter
+func (f HistogramFormatter) Format(ms Messages) (string, error) {
+	var labels []string
+	var counts []int
+	if f.ByCode {
+		byCode := ms.CountsByCode()
+		for code := range byCode {
+			labels = append(labels, code)
+		}
+		sort.Strings(labels)
+		for _, code := range labels {
+			counts = append(counts, byCode[code])
+		}
+	} else {
+		byLevel := ms.CountsByLevel()
+		levels := GetAllLevels()
+		sort.Slice(levels, func(i, j int) bool { return levels[i].sortOrder < levels[j].sortOrder })
+		for _, l := range levels {
+			labels = append(labels, l.String())
+			counts = append(counts, byLevel[l])
+		}
+	}
+
+	width := f.Width
+	if width <= 0 {
+		width = defaultHistogramWidth
+	}
+	labelWidth, maxCount := 0, 0
+	for i := range labels {
+		if len(labels[i]) > labelWidth {
+			labelWidth = len(labels[i])
+		}
+		if counts[i] > maxCount {
+			maxCount = counts[i]
+		}
+	}
+
+	lines := make([]string, 0, len(labels))
+	for i := range labels {
+		bar := 0
+		if maxCount > 0 {
+			// Round up, so that small counts are still visible
+			bar = (counts[i]*width + maxCount - 1) / maxCount
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %-*s %d", labelWidth, labels[i], width, strings.Repeat("#", bar), counts[i]))
+	}
+	return strings.Join(lines, "\n"), nil
+}
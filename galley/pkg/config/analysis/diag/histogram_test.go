@@ -0,0 +1,63 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestHistogramFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "A1", "Template: %q")
+
+	msgs := Messages{
+		NewMessage(et, nil, "1"),
+		NewMessage(et, nil, "2"),
+		NewMessage(et, nil, "3"),
+		NewMessage(et, nil, "4"),
+		NewMessage(wt, nil, "5"),
+	}
+
+	output, err := HistogramFormatter{Width: 8}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"Error   ######## 4\n" +
+			"Warning ##       1\n" +
+			"Info             0",
+	))
+
+	output, err = HistogramFormatter{ByCode: true, Width: 4}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"A1 #    1\n" +
+			"B1 #### 4",
+	))
+}
+
+func TestHistogramFormatter_Empty(t *testing.T) {
+	g := NewWithT(t)
+
+	output, err := HistogramFormatter{Width: 2}.Format(Messages{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"Error      0\n" +
+			"Warning    0\n" +
+			"Info       0",
+	))
+}
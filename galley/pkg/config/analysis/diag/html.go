@@ -0,0 +1,85 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"html/template"
+	"strings"
+)
+
+// HTMLFormatter renders messages as a self-contained HTML table, for embedding in web pages. Rows are colored by
+// level with inline styles, so no stylesheet is needed, and each message links to its documentation. All content
+// derived from messages is escaped. Messages are rendered in sorted order.
+type HTMLFormatter struct{}
+
+var _ Formatter = HTMLFormatter{}
+
+// htmlRowStyles are the inline styles of the table rows for each level
+var htmlRowStyles = map[Level]template.CSS{
+	Error:   "background-color: #f8d7da;",
+	Warning: "background-color: #fff3cd;",
+	Info:    "background-color: #d1ecf1;",
+}
+
+var htmlTemplate = template.Must(template.New("html").Parse(
+	`<table style="border-collapse: collapse; font-family: sans-serif;">
+  <thead>
+    <tr><th style="text-align: left; padding: 4px 8px;">Code</th><th style="text-align: left; padding: 4px 8px;">Level</th>` +
+		`<th style="text-align: left; padding: 4px 8px;">Resource</th><th style="text-align: left; padding: 4px 8px;">Message</th></tr>
+  </thead>
+  <tbody>
+{{- range .}}
+    <tr style="{{.Style}}"><td style="padding: 4px 8px;">{{.Code}}</td><td style="padding: 4px 8px;">{{.Level}}</td>` +
+		`<td style="padding: 4px 8px;">{{.Resource}}</td><td style="padding: 4px 8px;"><a href="{{.URL}}">{{.Text}}</a></td></tr>
+{{- end}}
+  </tbody>
+</table>`))
+
+type htmlRow struct {
+	Style    template.CSS
+	Code     string
+	Level    string
+	Resource string
+	URL      string
+	Text     string
+}
+
+// Format implements Formatter
+func (f HTMLFormatter) Format(ms Messages) (string, error) {
+	sorted := append(ms[:0:0], ms...)
+	sorted.Sort()
+
+	rows := make([]htmlRow, 0, len(sorted))
+	for i := range sorted {
+		m := &sorted[i]
+		row := htmlRow{
+			Style: htmlRowStyles[m.Type.Level()],
+			Code:  m.Type.Code(),
+			Level: m.Type.Level().String(),
+			URL:   m.documentationURL(),
+			Text:  m.Text(),
+		}
+		if m.Resource != nil {
+			row.Resource = m.originOf(m.Resource, true)
+		}
+		rows = append(rows, row)
+	}
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, rows); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
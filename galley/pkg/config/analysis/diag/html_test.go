@@ -0,0 +1,60 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/url"
+)
+
+func TestHTMLFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	it := NewMessageType(Info, "IST0043", "Cracker type not found: %s")
+
+	msgs := Messages{
+		NewMessage(it, MockResource("<pantry>"), `<script>alert("crackers")</script>`),
+		NewMessage(et, nil, "Brie & Feta"),
+	}
+
+	output, err := HTMLFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`<table style="border-collapse: collapse; font-family: sans-serif;">
+  <thead>
+    <tr><th style="text-align: left; padding: 4px 8px;">Code</th><th style="text-align: left; padding: 4px 8px;">Level</th>` +
+		`<th style="text-align: left; padding: 4px 8px;">Resource</th><th style="text-align: left; padding: 4px 8px;">Message</th></tr>
+  </thead>
+  <tbody>
+    <tr style="background-color: #f8d7da;"><td style="padding: 4px 8px;">IST0042</td><td style="padding: 4px 8px;">Error</td>` +
+		`<td style="padding: 4px 8px;"></td><td style="padding: 4px 8px;"><a href="` + url.ConfigAnalysis + `/ist0042/">` +
+		`Cheese type not found: &#34;Brie &amp; Feta&#34;</a></td></tr>
+    <tr style="background-color: #d1ecf1;"><td style="padding: 4px 8px;">IST0043</td><td style="padding: 4px 8px;">Info</td>` +
+		`<td style="padding: 4px 8px;">&lt;pantry&gt;</td><td style="padding: 4px 8px;"><a href="` + url.ConfigAnalysis + `/ist0043/">` +
+		`Cracker type not found: &lt;script&gt;alert(&#34;crackers&#34;)&lt;/script&gt;</a></td></tr>
+  </tbody>
+</table>`))
+}
+
+func TestHTMLFormatter_Empty(t *testing.T) {
+	g := NewWithT(t)
+
+	output, err := HTMLFormatter{}.Format(Messages{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring("<tbody>\n  </tbody>"))
+}
@@ -0,0 +1,130 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/xml"
+	"sort"
+	"strconv"
+)
+
+const junitSuiteName = "istio-analyze"
+
+// JUnitFormatter renders messages as a JUnit XML report with a single test suite, so that CI systems can display
+// them alongside test results. Each message becomes a test case: Error and Warning messages fail, while Info messages
+// pass with their text as output. Messages are rendered in sorted order.
+type JUnitFormatter struct {
+	// Properties, if set, are included in the properties of the test suite, e.g. to record the Istio version or
+	// cluster analyzed.
+	Properties map[string]string
+}
+
+var _ Formatter = JUnitFormatter{}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Format implements Formatter
+func (f JUnitFormatter) Format(ms Messages) (string, error) {
+	sorted := append(ms[:0:0], ms...)
+	sorted.Sort()
+
+	suite := junitTestSuite{
+		Name:       junitSuiteName,
+		Tests:      len(sorted),
+		Properties: f.properties(sorted),
+	}
+	for i := range sorted {
+		m := &sorted[i]
+		tc := junitTestCase{
+			Name:      junitTestCaseName(m),
+			ClassName: m.Type.Code(),
+		}
+		if m.Type.Level() == Info {
+			tc.SystemOut = m.Text()
+		} else {
+			suite.Failures++
+			tc.Failure = &junitFailure{
+				Type:    m.Type.Level().String(),
+				Message: m.Text(),
+				Text:    m.String(),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	out, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
+
+// properties returns the configured properties sorted by name, followed by the number of messages at each level as
+// "count.<Level>". The counts take precedence over configured properties of the same name.
+func (f JUnitFormatter) properties(ms Messages) []junitProperty {
+	counts := ms.CountsByLevel()
+	countNames := make(map[string]bool)
+	var countProps []junitProperty
+	for _, l := range []Level{Error, Warning, Info} {
+		name := "count." + l.String()
+		countNames[name] = true
+		countProps = append(countProps, junitProperty{Name: name, Value: strconv.Itoa(counts[l])})
+	}
+
+	var props []junitProperty
+	for name, value := range f.Properties {
+		if !countNames[name] {
+			props = append(props, junitProperty{Name: name, Value: value})
+		}
+	}
+	sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+	return append(props, countProps...)
+}
+
+func junitTestCaseName(m *Message) string {
+	if m.Resource == nil || m.Resource.Origin == nil {
+		return "(no resource)"
+	}
+	return m.Resource.Origin.FriendlyName()
+}
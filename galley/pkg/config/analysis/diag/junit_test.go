@@ -0,0 +1,71 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestJUnitFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	it := NewMessageType(Info, "IST0043", "Cracker type <%s> not found")
+
+	msgs := Messages{
+		NewMessage(it, MockResource("pantry"), "Saltine"),
+		NewMessage(et, MockResource("toppings"), "Feta"),
+		NewMessage(et, nil, "Brie"),
+	}
+
+	output, err := JUnitFormatter{Properties: map[string]string{
+		"version":       "1.12.0",
+		"cluster":       "primary",
+		"count.Warning": "ignored",
+	}}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="istio-analyze" tests="3" failures="2">
+    <properties>
+      <property name="cluster" value="primary"></property>
+      <property name="version" value="1.12.0"></property>
+      <property name="count.Error" value="2"></property>
+      <property name="count.Warning" value="0"></property>
+      <property name="count.Info" value="1"></property>
+    </properties>
+    <testcase name="(no resource)" classname="IST0042">
+      <failure type="Error" message="Cheese type not found: &#34;Brie&#34;">Error [IST0042] Cheese type not found: &#34;Brie&#34;</failure>
+    </testcase>
+    <testcase name="toppings" classname="IST0042">
+      <failure type="Error" message="Cheese type not found: &#34;Feta&#34;">Error [IST0042] (toppings) Cheese type not found: &#34;Feta&#34;</failure>
+    </testcase>
+    <testcase name="pantry" classname="IST0043">
+      <system-out>Cracker type &lt;Saltine&gt; not found</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>`))
+}
+
+func TestJUnitFormatter_NoProperties(t *testing.T) {
+	g := NewWithT(t)
+
+	output, err := JUnitFormatter{}.Format(Messages{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring(`<testsuite name="istio-analyze" tests="0" failures="0">`))
+	g.Expect(output).To(ContainSubstring(`<property name="count.Error" value="0"></property>`))
+}
@@ -0,0 +1,41 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+)
+
+// KubectlCommand returns a kubectl command that prints the resource the message is on, e.g.
+// "kubectl get virtualservices.networking.istio.io reviews -n prod -o yaml", or an empty string if the message has no
+// resource or its type isn't known. The resource type is qualified by its API group, if it has one, so that it is
+// unambiguous. The namespace flag is omitted for cluster-scoped resources.
+func (m *Message) KubectlCommand() string {
+	if m.Resource == nil || m.Resource.Metadata.Schema == nil {
+		return ""
+	}
+	namespace, _, name := m.resourceCoordinates()
+
+	s := m.Resource.Metadata.Schema
+	resourceType := s.Plural()
+	if s.Group() != "" {
+		resourceType += "." + s.Group()
+	}
+
+	if namespace == "" {
+		return fmt.Sprintf("kubectl get %s %s -o yaml", resourceType, name)
+	}
+	return fmt.Sprintf("kubectl get %s %s -n %s -o yaml", resourceType, name, namespace)
+}
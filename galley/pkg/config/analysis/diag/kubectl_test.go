@@ -0,0 +1,45 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+func TestMessage_KubectlCommand(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+
+	m := NewMessage(mt, mockSchemaResource("prod", "reviews"), "a")
+	g.Expect(m.KubectlCommand()).To(Equal("kubectl get virtualservices.networking.istio.io reviews -n prod -o yaml"))
+
+	node := MockResource("worker-1")
+	node.Metadata.FullName = resource.NewShortOrFullName("", "worker-1")
+	node.Metadata.Schema = collections.K8SCoreV1Nodes.Resource()
+	m = NewMessage(mt, node, "a")
+	g.Expect(m.KubectlCommand()).To(Equal("kubectl get nodes worker-1 -o yaml"))
+
+	m = NewMessage(mt, MockResource("unknown"), "a")
+	g.Expect(m.KubectlCommand()).To(BeEmpty())
+
+	m = NewMessage(mt, nil, "a")
+	g.Expect(m.KubectlCommand()).To(BeEmpty())
+}
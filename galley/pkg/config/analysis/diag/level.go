@@ -16,6 +16,7 @@ package diag
 
 import (
 	"strings"
+	"sync"
 )
 
 // Level is the severity level of a message.
@@ -43,6 +44,31 @@ var (
 	Error = Level{0, "Error"}
 )
 
+// fallbackLevel is the level of message types that don't declare one. See SetFallbackLevel.
+var fallbackLevel = struct {
+	sync.RWMutex
+	level Level
+}{
+	level: Warning,
+}
+
+// SetFallbackLevel sets the level reported by message types created with the zero Level, such as those of experimental
+// analyzers developed out of tree, so that their messages are still rendered with a meaningful level. It defaults to
+// Warning. Message types without a level can't be registered with RegisterMessageType, so the fallback never applies
+// to registered message types, including those of the msg package.
+func SetFallbackLevel(l Level) {
+	fallbackLevel.Lock()
+	defer fallbackLevel.Unlock()
+	fallbackLevel.level = l
+}
+
+// FallbackLevel returns the level reported by message types created with the zero Level.
+func FallbackLevel() Level {
+	fallbackLevel.RLock()
+	defer fallbackLevel.RUnlock()
+	return fallbackLevel.level
+}
+
 // GetAllLevels returns an arbitrarily ordered slice of all Levels defined.
 func GetAllLevels() []Level {
 	return []Level{Info, Warning, Error}
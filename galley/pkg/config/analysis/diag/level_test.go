@@ -0,0 +1,40 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFallbackLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(FallbackLevel()).To(Equal(Warning))
+
+	experimental := NewMessageType(Level{}, "EXP0001", "Experimental: {{.detail}}", WithArgs("detail"))
+	m := NewMessage(experimental, nil, "new analyzer")
+	g.Expect(m.Type.Level()).To(Equal(Warning))
+	g.Expect(m.String()).To(Equal("Warning [EXP0001] Experimental: new analyzer"))
+
+	SetFallbackLevel(Info)
+	defer SetFallbackLevel(Warning)
+	g.Expect(m.Type.Level()).To(Equal(Info))
+	g.Expect(m.String()).To(Equal("Info [EXP0001] Experimental: new analyzer"))
+
+	// Message types that declare a level are unaffected
+	g.Expect(NewMessageType(Error, "EXP0002", "Declared").Level()).To(Equal(Error))
+}
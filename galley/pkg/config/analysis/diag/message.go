@@ -17,8 +17,11 @@ package diag
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 
 	"istio.io/api/analysis/v1alpha1"
 	"istio.io/istio/pkg/config/resource"
@@ -35,10 +38,105 @@ type MessageType struct {
 
 	// TODO: Make this localizable
 	template string
+
+	// The name of the message type, if any
+	name string
+
+	// The minimum Istio version the message type applies to, if any
+	minVersion string
+
+	// The category of the message type, if any
+	category string
+
+	// A human readable description of the message type, if any
+	description string
+
+	// A longer explanation of the message type and how to resolve it, if any
+	longDescription string
+
+	// The URL of the documentation for the message type, if any
+	url string
+
+	// Whether the analyzers reporting messages of the type suggest fixes that can be applied automatically
+	autofix bool
+
+	// The names of the arguments of the message, in the order they are passed as parameters
+	args []string
+
+	// The parsed template, if the template refers to arguments by name. See usesTemplateSyntax.
+	parsed   *template.Template
+	parseErr error
+}
+
+// MessageTypeOption sets an optional property of a MessageType.
+type MessageTypeOption func(*MessageType)
+
+// WithName sets the name of a MessageType.
+func WithName(name string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.name = name
+	}
+}
+
+// WithCategory sets the category of a MessageType.
+func WithCategory(category string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.category = category
+	}
 }
 
-// Level returns the level of the MessageType
-func (m *MessageType) Level() Level { return m.level }
+// WithMinVersion sets the minimum Istio version a MessageType applies to, e.g. "1.10". See Messages.FilterByVersion.
+func WithMinVersion(v string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.minVersion = v
+	}
+}
+
+// WithDescription sets the description of a MessageType.
+func WithDescription(description string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.description = description
+	}
+}
+
+// WithLongDescription sets the long description of a MessageType: a longer explanation of the problem and how to
+// resolve it, used as help text where there is room for more than the description.
+func WithLongDescription(longDescription string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.longDescription = longDescription
+	}
+}
+
+// WithArgs sets the names of the arguments of a MessageType, in the order they are passed as parameters. Templates
+// using text/template syntax refer to the arguments by these names.
+func WithArgs(names ...string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.args = names
+	}
+}
+
+// WithURL sets the documentation URL of a MessageType.
+func WithURL(url string) MessageTypeOption {
+	return func(m *MessageType) {
+		m.url = url
+	}
+}
+
+// WithAutofix marks a MessageType as having fixes that can be applied automatically, for tooling that indicates which
+// messages are fixable. The messages themselves carry the fixes, as their SuggestedFix.
+func WithAutofix() MessageTypeOption {
+	return func(m *MessageType) {
+		m.autofix = true
+	}
+}
+
+// Level returns the level of the MessageType, or the fallback level if it was created without one
+func (m *MessageType) Level() Level {
+	if m.level == (Level{}) {
+		return FallbackLevel()
+	}
+	return m.level
+}
 
 // Code returns the code of the MessageType
 func (m *MessageType) Code() string { return m.code }
@@ -46,6 +144,30 @@ func (m *MessageType) Code() string { return m.code }
 // Template returns the message template used by the MessageType
 func (m *MessageType) Template() string { return m.template }
 
+// MinVersion returns the minimum Istio version the MessageType applies to, or empty if it applies to all versions
+func (m *MessageType) MinVersion() string { return m.minVersion }
+
+// Name returns the name of the MessageType, or empty if it has none
+func (m *MessageType) Name() string { return m.name }
+
+// Category returns the category of the MessageType, or empty if it has none
+func (m *MessageType) Category() string { return m.category }
+
+// Description returns the description of the MessageType, or empty if it has none
+func (m *MessageType) Description() string { return m.description }
+
+// LongDescription returns the long description of the MessageType, or empty if it has none
+func (m *MessageType) LongDescription() string { return m.longDescription }
+
+// HasAutofix returns whether messages of the MessageType come with fixes that can be applied automatically
+func (m *MessageType) HasAutofix() bool { return m.autofix }
+
+// URL returns the documentation URL of the MessageType, or empty if it has none
+func (m *MessageType) URL() string { return m.url }
+
+// Args returns the names of the arguments of the MessageType, in the order they are passed as parameters
+func (m *MessageType) Args() []string { return m.args }
+
 // Message is a specific diagnostic message
 // TODO: Implement using Analysis message API
 type Message struct {
@@ -63,6 +185,84 @@ type Message struct {
 
 	// Line is the line number of the error place in the message
 	Line int
+
+	// Related is an optional list of secondary resources involved in the message. The primary location of the
+	// message remains Resource.
+	Related []*resource.Instance
+
+	// SuggestedFix is an optional change that would resolve the message, if the analyzer can determine one
+	SuggestedFix *SuggestedFix
+
+	// Analyzer is the name of the analyzer that reported the message, or empty if it isn't known
+	Analyzer string
+
+	// Confidence is how certain the analyzer is that the message reports a real problem, if it says
+	Confidence Confidence
+
+	// Stack is the stack trace of the panic the message reports, if any, for debugging analyzers. It is only shown in
+	// verbose output.
+	Stack string
+
+	// text caches the rendered text of messages created with NewMessage, and is shared by their copies
+	text *renderedText
+}
+
+// renderedText is the text of a message, rendered when it is first needed. Sorting and deduping messages compares
+// their text repeatedly, while many messages are filtered out without ever being displayed, so rendering each message
+// at most once, and only if needed, saves most of the cost of rendering in large analysis runs.
+type renderedText struct {
+	once sync.Once
+
+	// The template and a copy of the parameters the text was rendered from. Copies of a message share its cache, so it
+	// only applies to those that still have the same template and parameters.
+	template   string
+	parameters []interface{}
+	text       string
+}
+
+// matches returns whether the text was rendered from the given template and parameters. Parameters are compared with
+// the copy taken when rendering, so replacing them, or assigning to their elements in place, invalidates the text.
+// Changes within the values they point to, such as the elements of a slice parameter, don't.
+func (r *renderedText) matches(template string, parameters []interface{}) bool {
+	if r.template != template || len(r.parameters) != len(parameters) {
+		return false
+	}
+	for i, p := range parameters {
+		if !sameParameter(r.parameters[i], p) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameParameter returns whether two parameters are equal. Matches are checked on every comparison while sorting, so
+// strings and integers, the most common parameters, are compared directly rather than with reflect.DeepEqual.
+func sameParameter(a, b interface{}) bool {
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case int:
+		b, ok := b.(int)
+		return ok && a == b
+	case uint32:
+		b, ok := b.(uint32)
+		return ok && a == b
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// SuggestedFix is a proposed change to the resource of a message that would resolve it.
+type SuggestedFix struct {
+	// Description is a human readable description of the fix
+	Description string `json:"description"`
+
+	// Patch is an optional JSON patch (RFC 6902) to apply to the resource
+	Patch string `json:"patch,omitempty"`
+
+	// Replacement is an optional snippet of configuration to replace the offending configuration with
+	Replacement string `json:"replacement,omitempty"`
 }
 
 // Unstructured returns this message as a JSON-style unstructured map
@@ -81,13 +281,27 @@ func (m *Message) Unstructured(includeOrigin bool) map[string]interface{} {
 			result["reference"] = loc
 		}
 	}
-	result["message"] = fmt.Sprintf(m.Type.Template(), m.Parameters...)
-
-	docQueryString := ""
-	if m.DocRef != "" {
-		docQueryString = fmt.Sprintf("?ref=%s", m.DocRef)
+	if includeOrigin && len(m.Related) > 0 {
+		result["related"] = m.RelatedOrigins()
+	}
+	if m.SuggestedFix != nil {
+		result["suggestedFix"] = m.SuggestedFix
+	}
+	if m.Confidence != (Confidence{}) {
+		result["confidence"] = m.Confidence.String()
+	}
+	result["message"] = m.Text()
+	if params := m.NamedParameters(); len(params) > 0 {
+		for name, p := range params {
+			// Errors don't serialize to anything useful, so use their text instead
+			if err, ok := p.(error); ok {
+				params[name] = err.Error()
+			}
+		}
+		result["args"] = params
 	}
-	result["documentationUrl"] = fmt.Sprintf("%s/%s/%s", url.ConfigAnalysis, strings.ToLower(m.Type.Code()), docQueryString)
+
+	result["documentationUrl"] = m.documentationURL()
 
 	return result
 }
@@ -95,14 +309,8 @@ func (m *Message) Unstructured(includeOrigin bool) map[string]interface{} {
 // UnstructuredAnalysisMessageBase returns this message as a JSON-style unstructured map in AnalaysisMessageBase
 // TODO(jasonwzm): Remove once message implements AnalysisMessageBase
 func (m *Message) UnstructuredAnalysisMessageBase() map[string]interface{} {
-	docQueryString := ""
-	if m.DocRef != "" {
-		docQueryString = fmt.Sprintf("?ref=%s", m.DocRef)
-	}
-	docURL := fmt.Sprintf("%s/%s/%s", url.ConfigAnalysis, strings.ToLower(m.Type.Code()), docQueryString)
-
 	mb := v1alpha1.AnalysisMessageBase{
-		DocumentationUrl: docURL,
+		DocumentationUrl: m.documentationURL(),
 		Level:            v1alpha1.AnalysisMessageBase_Level(v1alpha1.AnalysisMessageBase_Level_value[strings.ToUpper(m.Type.Level().String())]),
 		Type: &v1alpha1.AnalysisMessageBase_Type{
 			Code: m.Type.Code(),
@@ -120,27 +328,154 @@ func (m *Message) UnstructuredAnalysisMessageBase() map[string]interface{} {
 	return r
 }
 
+// documentationURL returns the URL of the documentation for the message, including its doc ref if it has one. This is
+// the URL of the message type, which may have been overridden with ApplyOverrides, or else the page for its code on
+// istio.io.
+func (m *Message) documentationURL() string {
+	base := m.Type.URL()
+	if base == "" {
+		base = fmt.Sprintf("%s/%s/", url.ConfigAnalysis, strings.ToLower(m.Type.Code()))
+	}
+	if m.DocRef == "" {
+		return base
+	}
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%sref=%s", base, separator, m.DocRef)
+}
+
 // Origin returns the origin of the message
 func (m *Message) Origin() string {
 	origin := ""
 	if m.Resource != nil {
-		loc := ""
-		if m.Resource.Origin.Reference() != nil {
-			loc = " " + m.Resource.Origin.Reference().String()
-			if m.Line != 0 {
-				loc = m.ReplaceLine(loc)
-			}
-		}
-		origin = " (" + m.Resource.Origin.FriendlyName() + loc + ")"
+		origin = " (" + m.originOf(m.Resource, true) + ")"
 	}
 	return origin
 }
 
+// splitReference splits a resource reference of the form "path[:line]" into its path and line. The line is zero if
+// the reference has none.
+func splitReference(ref string) (string, int) {
+	if i := strings.LastIndex(ref, ":"); i >= 0 {
+		if l, err := strconv.Atoi(strings.TrimSpace(ref[i+1:])); err == nil {
+			return ref[:i], l
+		}
+	}
+	return ref, 0
+}
+
+// sourceFile returns the file the resource was loaded from, or empty if it wasn't loaded from a file.
+func sourceFile(r *resource.Instance) string {
+	if r == nil || r.Origin == nil || r.Origin.Reference() == nil {
+		return ""
+	}
+	path, _ := splitReference(r.Origin.Reference().String())
+	return path
+}
+
+// RelatedOrigins returns the origins of the related resources of the message, in the order they were added.
+func (m *Message) RelatedOrigins() []string {
+	var origins []string
+	for _, r := range m.Related {
+		if r != nil {
+			origins = append(origins, m.originOf(r, false))
+		}
+	}
+	return origins
+}
+
+// resourceCoordinates returns the namespace, kind and name of the resource of the message. Any that are unknown,
+// including all of them when the message has no resource, are returned empty.
+func (m *Message) resourceCoordinates() (namespace, kind, name string) {
+	if m.Resource == nil {
+		return "", "", ""
+	}
+	namespace = m.Resource.Metadata.FullName.Namespace.String()
+	if namespace == "" && m.Resource.Origin != nil {
+		namespace = m.Resource.Origin.Namespace().String()
+	}
+	if m.Resource.Metadata.Schema != nil {
+		kind = m.Resource.Metadata.Schema.Kind()
+	}
+	return namespace, kind, m.Resource.Metadata.FullName.Name.String()
+}
+
+// ResourceKey identifies the resource of the message as namespace/kind/name, leaving out the namespace of
+// cluster-scoped resources and the kind of resources without a schema. It is empty if the message has no resource.
+func (m *Message) ResourceKey() string {
+	namespace, kind, name := m.resourceCoordinates()
+	var parts []string
+	for _, p := range []string{namespace, kind, name} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// originOf formats the origin of a resource, replacing the line of its reference with the line of the message if
+// requested.
+func (m *Message) originOf(r *resource.Instance, withLine bool) string {
+	loc := ""
+	if r.Origin.Reference() != nil {
+		loc = " " + r.Origin.Reference().String()
+		if withLine && m.Line != 0 {
+			loc = m.ReplaceLine(loc)
+		}
+	}
+	return r.Origin.FriendlyName() + loc
+}
+
 // String implements io.Stringer
 func (m *Message) String() string {
 	return fmt.Sprintf("%v [%v]%s %s",
 		m.Type.Level(), m.Type.Code(), m.Origin(),
-		fmt.Sprintf(m.Type.Template(), m.Parameters...))
+		m.Text())
+}
+
+// Text returns the text of the message, without its level, code or origin. Templates using text/template syntax
+// have the parameters substituted by argument name; other templates are formatted as printf format strings.
+func (m *Message) Text() string {
+	c := m.text
+	if c == nil {
+		return m.render()
+	}
+	c.once.Do(func() {
+		c.template, c.parameters, c.text = m.Type.Template(), append([]interface{}(nil), m.Parameters...), m.render()
+	})
+	if !c.matches(m.Type.Template(), m.Parameters) {
+		return m.render()
+	}
+	return c.text
+}
+
+func (m *Message) render() string {
+	if !usesTemplateSyntax(m.Type.Template()) {
+		return fmt.Sprintf(m.Type.Template(), m.Parameters...)
+	}
+	if m.Type.parseErr != nil {
+		return fmt.Sprintf("%s (error parsing template: %v)", m.Type.Template(), m.Type.parseErr)
+	}
+
+	var b strings.Builder
+	if err := m.Type.parsed.Execute(&b, m.NamedParameters()); err != nil {
+		return fmt.Sprintf("%s (error rendering template: %v)", m.Type.Template(), err)
+	}
+	return b.String()
+}
+
+// NamedParameters returns the parameters of the message keyed by the arg names declared by its type. Parameters
+// without a declared name are omitted.
+func (m *Message) NamedParameters() map[string]interface{} {
+	params := make(map[string]interface{}, len(m.Type.Args()))
+	for i, name := range m.Type.Args() {
+		if i < len(m.Parameters) {
+			params[name] = m.Parameters[i]
+		}
+	}
+	return params
 }
 
 // MarshalJSON satisfies the Marshaler interface
@@ -149,12 +484,19 @@ func (m *Message) MarshalJSON() ([]byte, error) {
 }
 
 // NewMessageType returns a new MessageType instance.
-func NewMessageType(level Level, code, template string) *MessageType {
-	return &MessageType{
+func NewMessageType(level Level, code, template string, opts ...MessageTypeOption) *MessageType {
+	mt := &MessageType{
 		level:    level,
 		code:     code,
 		template: template,
 	}
+	for _, opt := range opts {
+		opt(mt)
+	}
+	if usesTemplateSyntax(template) {
+		mt.parsed, mt.parseErr = parseTemplate(code, template)
+	}
+	return mt
 }
 
 // NewMessage returns a new Message instance from an existing type.
@@ -163,9 +505,19 @@ func NewMessage(mt *MessageType, r *resource.Instance, p ...interface{}) Message
 		Type:       mt,
 		Resource:   r,
 		Parameters: p,
+		text:       &renderedText{},
 	}
 }
 
+// WithOrigin returns a copy of the message attributed to a different resource, such as a higher-level resource the
+// user owns. The code, level and parameters of the copy are unchanged, but its line is cleared, since it referred to a
+// position within the original resource. The receiver is not modified.
+func (m Message) WithOrigin(r *resource.Instance) Message {
+	m.Resource = r
+	m.Line = 0
+	return m
+}
+
 // ReplaceLine replaces the line number from the input String method of Reference to the line number from Message
 func (m Message) ReplaceLine(l string) string {
 	colonSep := strings.Split(l, ":")
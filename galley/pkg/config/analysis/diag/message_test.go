@@ -16,6 +16,7 @@ package diag
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -41,6 +42,111 @@ func TestMessageWithResource_String(t *testing.T) {
 	g.Expect(m.String()).To(Equal(`Error [IST-0042] (toppings/cheese path/to/file) Cheese type not found: "Feta"`))
 }
 
+func TestMessage_Related(t *testing.T) {
+	g := NewWithT(t)
+	mt := NewMessageType(Error, "IST-0042", "Cheese type not found: %q")
+	m := NewMessage(mt, &resource.Instance{Origin: testOrigin{name: "toppings/cheese", ref: testReference{"path/to/file:3"}}}, "Feta")
+	m.Line = 5
+	m.Related = []*resource.Instance{
+		{Origin: testOrigin{name: "pantry/shelf", ref: testReference{"path/to/other:7"}}},
+		{Origin: testOrigin{name: "pantry/fridge"}},
+	}
+
+	g.Expect(m.Origin()).To(Equal(" (toppings/cheese path/to/file:5)"))
+	g.Expect(m.RelatedOrigins()).To(Equal([]string{"pantry/shelf path/to/other:7", "pantry/fridge"}))
+	g.Expect(m.Unstructured(true)["related"]).To(Equal([]string{"pantry/shelf path/to/other:7", "pantry/fridge"}))
+	g.Expect(m.Unstructured(false)).NotTo(HaveKey("related"))
+
+	m.Related = nil
+	g.Expect(m.Unstructured(true)).NotTo(HaveKey("related"))
+}
+
+func TestMessage_SuggestedFix(t *testing.T) {
+	g := NewWithT(t)
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	m := NewMessage(mt, nil, "Feta")
+	g.Expect(m.Unstructured(true)).NotTo(HaveKey("suggestedFix"))
+
+	m.SuggestedFix = &SuggestedFix{
+		Description: "Use a cheese that exists",
+		Patch:       `[{"op": "replace", "path": "/spec/cheese", "value": "Brie"}]`,
+	}
+	j, _ := json.Marshal(&m)
+	g.Expect(string(j)).To(ContainSubstring(`"suggestedFix":{"description":"Use a cheese that exists",` +
+		`"patch":"[{\"op\": \"replace\", \"path\": \"/spec/cheese\", \"value\": \"Brie\"}]"}`))
+}
+
+func TestMessage_Confidence(t *testing.T) {
+	g := NewWithT(t)
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	m := NewMessage(mt, nil, "Feta")
+	g.Expect(m.Unstructured(true)).NotTo(HaveKey("confidence"))
+
+	m.Confidence = LowConfidence
+	g.Expect(m.Unstructured(true)).To(HaveKeyWithValue("confidence", "Low"))
+}
+
+func TestMessage_WithOrigin(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	m := NewMessage(mt, MockResource("toppings"), "Feta")
+	m.Line = 12
+
+	moved := m.WithOrigin(MockResource("pizza"))
+	g.Expect(moved.String()).To(Equal(`Error [IST0042] (pizza) Cheese type not found: "Feta"`))
+	g.Expect(moved.Line).To(BeZero())
+	g.Expect(moved.Type).To(BeIdenticalTo(mt))
+
+	// The original is untouched
+	g.Expect(m.Resource.Origin.FriendlyName()).To(Equal("toppings"))
+	g.Expect(m.Line).To(Equal(12))
+
+	unattributed := m.WithOrigin(nil)
+	g.Expect(unattributed.String()).To(Equal(`Error [IST0042] Cheese type not found: "Feta"`))
+}
+
+// countingStringer counts how many times it is formatted
+type countingStringer struct {
+	calls *int
+}
+
+func (c countingStringer) String() string {
+	*c.calls++
+	return "Feta"
+}
+
+func TestMessage_TextIsCached(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	calls := 0
+	m := NewMessage(mt, nil, countingStringer{calls: &calls})
+	g.Expect(calls).To(BeZero())
+
+	g.Expect(m.Text()).To(Equal(`Cheese type not found: "Feta"`))
+	copied := m
+	g.Expect(copied.String()).To(Equal(`Error [IST0042] Cheese type not found: "Feta"`))
+	g.Expect(calls).To(Equal(1))
+
+	// Copies with different parameters don't use the cached text
+	copied.Parameters = []interface{}{"Brie"}
+	g.Expect(copied.Text()).To(Equal(`Cheese type not found: "Brie"`))
+	g.Expect(m.Text()).To(Equal(`Cheese type not found: "Feta"`))
+	g.Expect(calls).To(Equal(1))
+
+	// Nor do messages whose parameters were changed in place
+	edited := NewMessage(mt, nil, "Gouda")
+	g.Expect(edited.Text()).To(Equal(`Cheese type not found: "Gouda"`))
+	edited.Parameters[0] = "Edam"
+	g.Expect(edited.Text()).To(Equal(`Cheese type not found: "Edam"`))
+
+	// Messages created without NewMessage are rendered every time
+	literal := Message{Type: mt, Parameters: []interface{}{countingStringer{calls: &calls}}}
+	_, _ = literal.Text(), literal.Text()
+	g.Expect(calls).To(Equal(3))
+}
+
 func TestMessage_Unstructured(t *testing.T) {
 	g := NewWithT(t)
 	mt := NewMessageType(Error, "IST-0042", "Cheese type not found: %q")
@@ -55,6 +161,23 @@ func TestMessage_Unstructured(t *testing.T) {
 	g.Expect(m.Unstructured(false)).To(Not(HaveKey("origin")))
 }
 
+func TestMessage_UnstructuredArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese {{.cheese}} not found: {{.err}}", WithArgs("cheese", "err"))
+	m := NewMessage(mt, nil, "Feta", errors.New("sold out"))
+	g.Expect(m.NamedParameters()).To(Equal(map[string]interface{}{"cheese": "Feta", "err": errors.New("sold out")}))
+	g.Expect(m.Unstructured(false)["args"]).To(Equal(map[string]interface{}{"cheese": "Feta", "err": "sold out"}))
+
+	j, err := json.Marshal(&m)
+	g.Expect(err).To(BeNil())
+	g.Expect(string(j)).To(ContainSubstring(`"args":{"cheese":"Feta","err":"sold out"}`))
+
+	// Messages without declared args have no args
+	m = NewMessage(NewMessageType(Error, "IST0042", "Cheese type not found: %q"), nil, "Feta")
+	g.Expect(m.Unstructured(false)).NotTo(HaveKey("args"))
+}
+
 func TestMessageWithDocRef(t *testing.T) {
 	g := NewWithT(t)
 	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
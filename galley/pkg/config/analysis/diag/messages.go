@@ -15,9 +15,16 @@
 package diag
 
 import (
+	"fmt"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
 
 	"istio.io/istio/operator/pkg/object"
+	"istio.io/istio/pkg/config/resource"
 )
 
 // Messages is a slice of Message items.
@@ -28,9 +35,10 @@ func (ms *Messages) Add(m ...Message) {
 	*ms = append(*ms, m...)
 }
 
-// Sort the message lexicographically by level, code, resource origin name, then string.
+// Sort the message lexicographically by level, code, resource origin name, then string. The sort is stable: messages
+// that compare equal, such as those with the same string but different doc refs, keep their relative order.
 func (ms *Messages) Sort() {
-	sort.Slice(*ms, func(i, j int) bool {
+	sort.SliceStable(*ms, func(i, j int) bool {
 		a, b := (*ms)[i], (*ms)[j]
 		switch {
 		case a.Type.Level() != b.Type.Level():
@@ -51,20 +59,31 @@ func (ms *Messages) Sort() {
 
 // SortedDedupedCopy returns a different sorted (and deduped) Messages struct.
 func (ms *Messages) SortedDedupedCopy() Messages {
+	deduped, _ := ms.DedupWithCounts()
+	return deduped
+}
+
+// DedupWithCounts returns the same sorted and deduped copy of the messages as SortedDedupedCopy, along with the
+// number of times each message occurred, keyed by its String, which is what identifies duplicates. Renderers can use
+// the counts to note how many times repeated findings occurred, e.g. "... (x14)".
+func (ms *Messages) DedupWithCounts() (Messages, map[string]int) {
 	newMs := append((*ms)[:0:0], *ms...)
 	newMs.Sort()
 
 	// Take advantage of the fact that the list is already sorted to dedupe
 	// messages (any duplicates should be adjacent).
 	var deduped Messages
+	counts := make(map[string]int)
 	for _, m := range newMs {
 		// Two messages are duplicates if they have the same string representation.
-		if len(deduped) != 0 && deduped[len(deduped)-1].String() == m.String() {
+		key := m.String()
+		counts[key]++
+		if len(deduped) != 0 && deduped[len(deduped)-1].String() == key {
 			continue
 		}
 		deduped = append(deduped, m)
 	}
-	return deduped
+	return deduped, counts
 }
 
 // SetDocRef sets the doc URL reference tracker for the messages
@@ -86,6 +105,12 @@ func (ms *Messages) FilterOutLowerThan(outputLevel Level) Messages {
 	return outputMessages
 }
 
+// ActionableOnly returns the Warning and Error messages, leaving out Info messages, which usually don't call for any
+// change. It is a shortcut for FilterOutLowerThan(Warning).
+func (ms *Messages) ActionableOnly() Messages {
+	return ms.FilterOutLowerThan(Warning)
+}
+
 func (ms *Messages) FilterOutBasedOnResources(resources object.K8sObjects) Messages {
 	outputMessages := Messages{}
 	for _, m := range *ms {
@@ -98,3 +123,457 @@ func (ms *Messages) FilterOutBasedOnResources(resources object.K8sObjects) Messa
 	}
 	return outputMessages
 }
+
+// PartitionByLevel splits the messages into separate collections keyed by level. Each partition follows the same
+// ordering as Sort.
+func (ms *Messages) PartitionByLevel() map[Level]Messages {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+
+	partitions := make(map[Level]Messages)
+	for _, m := range sorted {
+		partitions[m.Type.Level()] = append(partitions[m.Type.Level()], m)
+	}
+	return partitions
+}
+
+// GroupByResource splits the messages into separate collections keyed by the resource they are on, as formatted by
+// Message.ResourceKey. Messages without a resource are keyed by the empty string. Each group follows the same ordering
+// as Sort.
+func (ms *Messages) GroupByResource() map[string]Messages {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+
+	groups := make(map[string]Messages)
+	for _, m := range sorted {
+		key := m.ResourceKey()
+		groups[key] = append(groups[key], m)
+	}
+	return groups
+}
+
+// WorstLevel returns the most severe level of any of the messages, or false if there are none.
+func (ms *Messages) WorstLevel() (Level, bool) {
+	if len(*ms) == 0 {
+		return Level{}, false
+	}
+	worst := (*ms)[0].Type.Level()
+	for _, m := range (*ms)[1:] {
+		if l := m.Type.Level(); !worst.IsWorseThanOrEqualTo(l) {
+			worst = l
+		}
+	}
+	return worst, true
+}
+
+// CountsByLevel returns the number of messages at each level. Levels without any messages are omitted.
+func (ms *Messages) CountsByLevel() map[Level]int {
+	counts := make(map[Level]int)
+	for _, m := range *ms {
+		counts[m.Type.Level()]++
+	}
+	return counts
+}
+
+// Summary returns a one line summary of the number of messages at each level, e.g. "Found 3 message(s): 1 Error, 2
+// Warning, 0 Info."
+func (ms *Messages) Summary() string {
+	if len(*ms) == 0 {
+		return "Found no messages."
+	}
+	return fmt.Sprintf("Found %d message(s): %s.", len(*ms), ms.levelCounts())
+}
+
+// StringWithSummary renders the messages in the order given, one per line as by Message.String, followed by a line
+// with their Summary if withSummary is set.
+func (ms *Messages) StringWithSummary(withSummary bool) string {
+	lines := make([]string, 0, len(*ms)+1)
+	for i := range *ms {
+		lines = append(lines, (*ms)[i].String())
+	}
+	if withSummary {
+		lines = append(lines, ms.Summary())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderWithBudget renders the messages in sorted order, one per line as with String, in at most maxBytes bytes, e.g.
+// to fit the body size limit of a chat or ticketing system. Messages that don't fit are left out whole rather than
+// truncated, and a final line notes how many, e.g. "... 3 more omitted". If maxBytes isn't positive, all the messages
+// are rendered.
+func (ms *Messages) RenderWithBudget(maxBytes int) string {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+	lines := make([]string, 0, len(sorted))
+	for i := range sorted {
+		lines = append(lines, sorted[i].String())
+	}
+	if maxBytes <= 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	// sizes[n] is the number of bytes taken by the first n lines, including the line breaks after them
+	sizes := make([]int, len(lines)+1)
+	for i, l := range lines {
+		sizes[i+1] = sizes[i] + len(l) + 1
+	}
+	if sizes[len(lines)]-1 <= maxBytes {
+		return strings.Join(lines, "\n")
+	}
+
+	// Keep as many lines as fit along with the note of how many were left out
+	n := len(lines)
+	for n > 0 && sizes[n]+len(omitted(n, len(lines))) > maxBytes {
+		n--
+	}
+	if n == 0 && len(omitted(0, len(lines))) > maxBytes {
+		return ""
+	}
+	return strings.Join(append(lines[:n:n], omitted(n, len(lines))), "\n")
+}
+
+// omitted notes how many of the given number of messages were left out after rendering the first n
+func omitted(n, total int) string {
+	return fmt.Sprintf("%s %d more omitted", Ellipsis, total-n)
+}
+
+// levelCounts lists the number of messages at every level, most severe first, e.g. "1 Error, 2 Warning, 0 Info".
+func (ms *Messages) levelCounts() string {
+	byLevel := ms.CountsByLevel()
+	levels := GetAllLevels()
+	sort.Slice(levels, func(i, j int) bool { return levels[i].sortOrder < levels[j].sortOrder })
+	counts := make([]string, 0, len(levels))
+	for _, l := range levels {
+		counts = append(counts, fmt.Sprintf("%d %v", byLevel[l], l))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// CountsByCode returns the number of messages with each code.
+func (ms *Messages) CountsByCode() map[string]int {
+	counts := make(map[string]int)
+	for _, m := range *ms {
+		counts[m.Type.Code()]++
+	}
+	return counts
+}
+
+// Apply returns the result of calling fn on each message, in their original order, e.g. to rewrite their origins.
+// Messages for which fn returns a message without a type, such as the zero Message, are dropped. The messages themselves
+// are left unchanged, although fn receives copies that share any pointer fields, such as the resource.
+func (ms *Messages) Apply(fn func(Message) Message) Messages {
+	var result Messages
+	for _, m := range *ms {
+		if out := fn(m); out.Type != nil {
+			result = append(result, out)
+		}
+	}
+	return result
+}
+
+// Codes returns the distinct codes of the messages, sorted.
+func (ms *Messages) Codes() []string {
+	counts := ms.CountsByCode()
+	codes := make([]string, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// FirstByCode returns the first message with the given code in the order of Sort, or false if there is none.
+func (ms *Messages) FirstByCode(code string) (Message, bool) {
+	var matching Messages
+	for _, m := range *ms {
+		if m.Type.Code() == code {
+			matching = append(matching, m)
+		}
+	}
+	if len(matching) == 0 {
+		return Message{}, false
+	}
+	matching.Sort()
+	return matching[0], true
+}
+
+// Each calls fn for each message in the order of Sort, along with its 0-based position in that order. The messages
+// themselves are left in their original order.
+func (ms *Messages) Each(fn func(i int, m Message)) {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+	for i, m := range sorted {
+		fn(i, m)
+	}
+}
+
+// WalkByResource calls fn once for each distinct resource origin of the messages, with the messages for that origin.
+// Origins are visited in order of their Comparator, preceded by the messages without any resource, for which the
+// origin is nil. The messages passed for each origin follow the same ordering as Sort. Walking stops at the first
+// error returned by fn, which is returned.
+func (ms *Messages) WalkByResource(fn func(origin resource.Origin, ms Messages) error) error {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+	// Sort already orders messages without a resource first, but only orders by origin within each level and code
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Resource, sorted[j].Resource
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		default:
+			return a.Origin.Comparator() < b.Origin.Comparator()
+		}
+	})
+
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && sameOrigin(sorted[start].Resource, sorted[end].Resource) {
+			end++
+		}
+
+		var origin resource.Origin
+		if sorted[start].Resource != nil {
+			origin = sorted[start].Resource.Origin
+		}
+		if err := fn(origin, sorted[start:end:end]); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+func sameOrigin(a, b *resource.Instance) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Origin.Comparator() == b.Origin.Comparator()
+}
+
+// CapPerCode returns a sorted copy of the messages keeping at most n messages with each code, along with the number
+// of messages dropped for each code that exceeded it, e.g. so that output can note how many more there are. The
+// messages kept are the first ones in the order of Sort. If n isn't positive, all the messages are kept.
+func (ms *Messages) CapPerCode(n int) (Messages, map[string]int) {
+	sorted := append((*ms)[:0:0], *ms...)
+	sorted.Sort()
+	if n <= 0 {
+		return sorted, map[string]int{}
+	}
+
+	kept := make(map[string]int)
+	dropped := make(map[string]int)
+	result := sorted[:0]
+	for _, m := range sorted {
+		code := m.Type.Code()
+		if kept[code] >= n {
+			dropped[code]++
+			continue
+		}
+		kept[code]++
+		result = append(result, m)
+	}
+	return result, dropped
+}
+
+// FilterByConfidence returns the messages reported with at least the given confidence. Messages whose analyzer didn't
+// set a confidence count as having high confidence, so are always kept.
+func (ms *Messages) FilterByConfidence(min Confidence) Messages {
+	var result Messages
+	for _, m := range *ms {
+		if m.Confidence.IsAtLeast(min) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// OnlyWithSuggestions returns the messages that have a suggested fix, e.g. for tooling that applies them.
+func (ms *Messages) OnlyWithSuggestions() Messages {
+	var result Messages
+	for _, m := range *ms {
+		if m.SuggestedFix != nil {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// FilterSince returns the messages on resources loaded from any of the given changed files, e.g. the files changed
+// since the base of a pull request. Messages on resources that weren't loaded from a file are excluded. Paths match if
+// they are equal once cleaned, or if the resource's path ends with the changed path, so that repository-relative
+// changed paths match resources loaded by absolute path.
+func (ms *Messages) FilterSince(changedPaths ...string) Messages {
+	changed := make([]string, 0, len(changedPaths))
+	for _, p := range changedPaths {
+		changed = append(changed, filepath.ToSlash(filepath.Clean(p)))
+	}
+
+	var result Messages
+	for _, m := range *ms {
+		path := sourceFile(m.Resource)
+		if path == "" {
+			continue
+		}
+		path = filepath.ToSlash(filepath.Clean(path))
+		for _, c := range changed {
+			if path == c || strings.HasSuffix(path, "/"+c) {
+				result = append(result, m)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// FilterByVersion returns the messages whose type applies to the given Istio version, dropping those whose minimum
+// version is later. Versions are compared semantically on their major, minor and patch segments only, so pre-release
+// builds of a version count as that version. Messages whose type has no minimum version, or one that can't be parsed,
+// are kept.
+func (ms *Messages) FilterByVersion(v string) (Messages, error) {
+	analyzed, err := version.NewVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Istio version %q: %v", v, err)
+	}
+
+	var result Messages
+	for _, m := range *ms {
+		if mv := m.Type.MinVersion(); mv != "" {
+			if minVersion, err := version.NewVersion(mv); err == nil && analyzed.Core().LessThan(minVersion.Core()) {
+				continue
+			}
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// AffectedResources returns the number of distinct resources the messages are on, as identified by their origin.
+// Messages without a resource are not counted.
+func (ms *Messages) AffectedResources() int {
+	origins := make(map[string]bool)
+	for _, m := range *ms {
+		if m.Resource != nil && m.Resource.Origin != nil {
+			origins[m.Resource.Origin.Comparator()] = true
+		}
+	}
+	return len(origins)
+}
+
+// AffectedNamespaces returns the number of distinct namespaces of the resources the messages are on. Cluster-scoped
+// resources and messages without a resource don't belong to a namespace, so are not counted.
+func (ms *Messages) AffectedNamespaces() int {
+	namespaces := make(map[string]bool)
+	for i := range *ms {
+		if namespace, _, _ := (*ms)[i].resourceCoordinates(); namespace != "" {
+			namespaces[namespace] = true
+		}
+	}
+	return len(namespaces)
+}
+
+// FilterByMessageRegex returns the messages whose rendered text matches the given regular expression, as with
+// regexp.MatchString, so that it matches anywhere in the text unless anchored. It returns an error if the pattern is
+// invalid.
+func (ms *Messages) FilterByMessageRegex(pattern string) (Messages, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message pattern %q: %v", pattern, err)
+	}
+
+	var result Messages
+	for _, m := range *ms {
+		if re.MatchString(m.Text()) {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+// FilterByAnalyzer returns the messages reported by the named analyzer. Messages that aren't attributed to an analyzer
+// are excluded.
+func (ms *Messages) FilterByAnalyzer(name string) Messages {
+	var result Messages
+	for _, m := range *ms {
+		if m.Analyzer != "" && m.Analyzer == name {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// WithPosition returns the messages whose position is known down to the line, as reported by Message.Position.
+func (ms *Messages) WithPosition() Messages {
+	var result Messages
+	for _, m := range *ms {
+		if _, line := m.Position(); line != 0 {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// WithoutPosition returns the messages whose position isn't known down to the line, because they aren't on a resource
+// loaded from a file or neither the origin nor the analyzer recorded a line. Analyzer authors can use it to find
+// messages to make more precise.
+func (ms *Messages) WithoutPosition() Messages {
+	var result Messages
+	for _, m := range *ms {
+		if _, line := m.Position(); line == 0 {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// Validate returns an error describing the first message that is inconsistent: one without a message type, with a
+// type whose code isn't registered with RegisterMessageType or is registered with a different template, or with an
+// unknown level. Types are matched by code and template rather than identity, since policies such as OverrideLevels
+// copy the type to change its level. It is a check for analyzer bugs, such as messages of types that out-of-tree analyzers forgot to register.
+func (ms *Messages) Validate() error {
+	for i, m := range *ms {
+		if m.Type == nil {
+			return fmt.Errorf("message at index %d has no message type", i)
+		}
+		registered, ok := LookupMessageType(m.Type.Code())
+		if !ok {
+			return fmt.Errorf("message at index %d has code %q, which is not registered", i, m.Type.Code())
+		}
+		if registered.Template() != m.Type.Template() {
+			return fmt.Errorf("message at index %d has code %q, which is registered with a different template", i, m.Type.Code())
+		}
+		if !containsLevel(GetAllLevels(), m.Type.Level()) {
+			return fmt.Errorf("message at index %d with code %q has unknown level %q", i, m.Type.Code(), m.Type.Level())
+		}
+	}
+	return nil
+}
+
+func containsLevel(levels []Level, l Level) bool {
+	for _, other := range levels {
+		if other == l {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOrigins returns an error if any message has no resource origin, listing the distinct codes of those
+// messages in order. Analyzers should attribute every message to a resource, so this is a check for analyzer bugs.
+func (ms *Messages) ValidateOrigins() error {
+	codes := make(map[string]bool)
+	for _, m := range *ms {
+		if m.Resource == nil || m.Resource.Origin == nil {
+			codes[m.Type.Code()] = true
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	var sorted []string
+	for c := range codes {
+		sorted = append(sorted, c)
+	}
+	sort.Strings(sorted)
+	return fmt.Errorf("messages with codes %s have no resource origin", strings.Join(sorted, ", "))
+}
@@ -0,0 +1,45 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"testing"
+)
+
+// BenchmarkMessages_SortDedupeFilter models a large analysis run, in which messages are sorted and deduped, but most
+// are filtered out before being rendered. Caching the rendered text of messages took it from about 287ms, 85MB and
+// 2.15M allocations per run to about 130ms, 33MB and 1.04M allocations, including the copies of the parameters taken
+// to invalidate the cache when they change.
+func BenchmarkMessages_SortDedupeFilter(b *testing.B) {
+	types := []*MessageType{
+		NewMessageType(Error, "B1", "Referenced {{.reftype}} not found: {{quote .refval}}", WithArgs("reftype", "refval")),
+		NewMessageType(Info, "C1", "Referenced %s not found: %q"),
+	}
+	r := MockResource("SoapBubble")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msgs := make(Messages, 0, 10000)
+		for j := 0; j < cap(msgs); j++ {
+			msgs.Add(NewMessage(types[j%len(types)], r, "host", fmt.Sprintf("reviews-%d", j%1000)))
+		}
+
+		deduped := msgs.SortedDedupedCopy()
+		for _, m := range deduped.FilterOutLowerThan(Error) {
+			_ = m.String()
+		}
+	}
+}
@@ -15,10 +15,13 @@
 package diag
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	. "github.com/onsi/gomega"
 
+	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/url"
 )
 
@@ -86,6 +89,38 @@ func TestMessages_SortWithNilOrigin(t *testing.T) {
 	g.Expect(msgs).To(Equal(expectedMsgs))
 }
 
+func TestMessages_SortIsStable(t *testing.T) {
+	g := NewWithT(t)
+
+	// Interleave enough messages from two resources that the sort has to move them around, and doesn't fall back to
+	// insertion sort, which is stable anyway
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	var msgs Messages
+	var expectedA, expectedB []string
+	for i := 0; i < 50; i++ {
+		name := "B"
+		if i%3 == 0 {
+			name = "A"
+		}
+		m := NewMessage(mt, MockResource(name), "shared")
+		m.DocRef = fmt.Sprint(i)
+		msgs = append(msgs, m)
+		if name == "A" {
+			expectedA = append(expectedA, m.DocRef)
+		} else {
+			expectedB = append(expectedB, m.DocRef)
+		}
+	}
+
+	msgs.Sort()
+
+	var refs []string
+	for _, m := range msgs {
+		refs = append(refs, m.DocRef)
+	}
+	g.Expect(refs).To(Equal(append(expectedA, expectedB...)))
+}
+
 func TestMessages_SortedCopy(t *testing.T) {
 	g := NewWithT(t)
 
@@ -114,6 +149,25 @@ func TestMessages_SortedCopy(t *testing.T) {
 	g.Expect(newMsgs).To(Equal(expectedMsgs))
 }
 
+func TestMessages_DedupWithCounts(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	a := NewMessage(mt, MockResource("A"), "a")
+	b := NewMessage(mt, MockResource("B"), "b")
+
+	msgs := Messages{b, a, b, b}
+	deduped, counts := msgs.DedupWithCounts()
+	g.Expect(deduped).To(Equal(Messages{a, b}))
+	g.Expect(deduped).To(Equal(msgs.SortedDedupedCopy()))
+	g.Expect(counts).To(Equal(map[string]int{a.String(): 1, b.String(): 3}))
+
+	var empty Messages
+	deduped, counts = empty.DedupWithCounts()
+	g.Expect(deduped).To(BeEmpty())
+	g.Expect(counts).To(BeEmpty())
+}
+
 func TestMessages_SetRefDoc(t *testing.T) {
 	g := NewWithT(t)
 
@@ -185,3 +239,504 @@ func TestMessages_FilterOutAll(t *testing.T) {
 
 	g.Expect(filteredMsgs).To(Equal(expectedMsgs))
 }
+
+func TestMessages_PartitionByLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	firstMsg := NewMessage(
+		NewMessageType(Error, "B1", "Template: %q"),
+		MockResource("B"),
+		"B",
+	)
+	secondMsg := NewMessage(
+		NewMessageType(Error, "A1", "Template: %q"),
+		MockResource("B"),
+		"B",
+	)
+	thirdMsg := NewMessage(
+		NewMessageType(Warning, "C1", "Template: %q"),
+		MockResource("B"),
+		"B",
+	)
+
+	msgs := Messages{firstMsg, secondMsg, thirdMsg}
+	partitions := msgs.PartitionByLevel()
+
+	g.Expect(partitions).To(HaveLen(2))
+	g.Expect(partitions[Error]).To(Equal(Messages{secondMsg, firstMsg}))
+	g.Expect(partitions[Warning]).To(Equal(Messages{thirdMsg}))
+	g.Expect(partitions[Info]).To(BeEmpty())
+
+	// The original collection is left untouched
+	g.Expect(msgs).To(Equal(Messages{firstMsg, secondMsg, thirdMsg}))
+}
+
+func TestMessages_Counts(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := Messages{
+		NewMessage(NewMessageType(Error, "B1", "Template: %q"), nil, "B"),
+		NewMessage(NewMessageType(Error, "B1", "Template: %q"), nil, "C"),
+		NewMessage(NewMessageType(Warning, "A1", "Template: %q"), nil, "B"),
+	}
+
+	g.Expect(msgs.CountsByLevel()).To(Equal(map[Level]int{Error: 2, Warning: 1}))
+	g.Expect(msgs.CountsByCode()).To(Equal(map[string]int{"B1": 2, "A1": 1}))
+	g.Expect(msgs.Codes()).To(Equal([]string{"A1", "B1"}))
+
+	var empty Messages
+	g.Expect(empty.Codes()).To(BeEmpty())
+}
+
+func TestMessages_Apply(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	msgs := Messages{
+		NewMessage(et, nil, "a"),
+		NewMessage(wt, nil, "b"),
+		NewMessage(et, nil, "c"),
+	}
+
+	applied := msgs.Apply(func(m Message) Message {
+		if m.Type == wt {
+			return Message{}
+		}
+		m.DocRef = "applied"
+		return m
+	})
+	g.Expect(applied).To(HaveLen(2))
+	g.Expect(applied[0].Text()).To(Equal(`Template: "a"`))
+	g.Expect(applied[1].Text()).To(Equal(`Template: "c"`))
+	for _, m := range applied {
+		g.Expect(m.DocRef).To(Equal("applied"))
+	}
+
+	// The original collection is left untouched
+	g.Expect(msgs).To(HaveLen(3))
+	for _, m := range msgs {
+		g.Expect(m.DocRef).To(BeEmpty())
+	}
+}
+
+func TestMessages_FirstByCode(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	msgs := Messages{
+		NewMessage(NewMessageType(Error, "A1", "Template: %q"), MockResource("a"), "a"),
+		NewMessage(mt, MockResource("c"), "c"),
+		NewMessage(mt, MockResource("b"), "b"),
+	}
+
+	first, ok := msgs.FirstByCode("B1")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(first.Resource).To(BeIdenticalTo(msgs[2].Resource))
+
+	_, ok = msgs.FirstByCode("C1")
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestMessages_FilterByMessageRegex(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Port not found: %q")
+	http := NewMessage(mt, nil, "http")
+	grpc := NewMessage(mt, nil, "grpc-web")
+	msgs := Messages{http, grpc}
+
+	filtered, err := msgs.FilterByMessageRegex(`"grpc`)
+	g.Expect(err).To(BeNil())
+	g.Expect(filtered).To(Equal(Messages{grpc}))
+
+	filtered, err = msgs.FilterByMessageRegex(`^Port not found: "[a-z]+"$`)
+	g.Expect(err).To(BeNil())
+	g.Expect(filtered).To(Equal(Messages{http}))
+
+	filtered, err = msgs.FilterByMessageRegex("missing")
+	g.Expect(err).To(BeNil())
+	g.Expect(filtered).To(BeEmpty())
+
+	_, err = msgs.FilterByMessageRegex("(")
+	g.Expect(err).To(MatchError(ContainSubstring(`invalid message pattern "("`)))
+}
+
+func TestMessages_Each(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	a := NewMessage(mt, MockResource("A"), "a")
+	b := NewMessage(mt, MockResource("B"), "b")
+	msgs := Messages{b, a}
+
+	var visited Messages
+	var positions []int
+	msgs.Each(func(i int, m Message) {
+		positions = append(positions, i)
+		visited = append(visited, m)
+	})
+	g.Expect(positions).To(Equal([]int{0, 1}))
+	g.Expect(visited).To(Equal(Messages{a, b}))
+	g.Expect(msgs).To(Equal(Messages{b, a}))
+
+	var empty Messages
+	empty.Each(func(int, Message) { t.Fatal("unexpected message") })
+}
+
+func TestMessages_WalkByResource(t *testing.T) {
+	g := NewWithT(t)
+
+	firstMsg := NewMessage(
+		NewMessageType(Warning, "A1", "Template: %q"),
+		nil,
+		"B",
+	)
+	secondMsg := NewMessage(
+		NewMessageType(Error, "B1", "Template: %q"),
+		MockResource("A"),
+		"B",
+	)
+	thirdMsg := NewMessage(
+		NewMessageType(Warning, "A1", "Template: %q"),
+		MockResource("A"),
+		"B",
+	)
+	fourthMsg := NewMessage(
+		NewMessageType(Error, "A1", "Template: %q"),
+		MockResource("B"),
+		"B",
+	)
+
+	msgs := Messages{fourthMsg, thirdMsg, secondMsg, firstMsg}
+
+	var origins []resource.Origin
+	var groups []Messages
+	err := msgs.WalkByResource(func(origin resource.Origin, ms Messages) error {
+		origins = append(origins, origin)
+		groups = append(groups, ms)
+		return nil
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(origins).To(Equal([]resource.Origin{nil, testOrigin{name: "A"}, testOrigin{name: "B"}}))
+	g.Expect(groups).To(Equal([]Messages{{firstMsg}, {secondMsg, thirdMsg}, {fourthMsg}}))
+
+	visited := 0
+	stop := errors.New("stop")
+	err = msgs.WalkByResource(func(origin resource.Origin, ms Messages) error {
+		visited++
+		return stop
+	})
+	g.Expect(err).To(Equal(stop))
+	g.Expect(visited).To(Equal(1))
+}
+
+func TestMessages_FilterSince(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST-0042", "Cheese type not found: %q")
+	fromFile := func(name, ref string) *resource.Instance {
+		return &resource.Instance{Origin: testOrigin{name: name, ref: testReference{ref}}}
+	}
+	changed := NewMessage(mt, fromFile("changed", "/work/repo/config/changed.yaml:12"), "Feta")
+	unchanged := NewMessage(mt, fromFile("unchanged", "/work/repo/config/unchanged.yaml:3"), "Brie")
+	relative := NewMessage(mt, fromFile("relative", "other/changed.yaml"), "Gouda")
+	cluster := NewMessage(mt, &resource.Instance{Origin: testOrigin{name: "cluster"}}, "Edam")
+	noResource := NewMessage(mt, nil, "Cheddar")
+
+	ms := Messages{changed, unchanged, relative, cluster, noResource}
+	g.Expect(ms.FilterSince("config/changed.yaml", "./other/changed.yaml")).To(Equal(Messages{changed, relative}))
+
+	// Paths match on whole path components only
+	g.Expect(ms.FilterSince("changed.yaml")).To(Equal(Messages{changed, relative}))
+	g.Expect(ms.FilterSince("g/changed.yaml")).To(BeEmpty())
+	g.Expect(ms.FilterSince()).To(BeEmpty())
+}
+
+func TestMessages_FilterByVersion(t *testing.T) {
+	g := NewWithT(t)
+
+	always := NewMessage(NewMessageType(Error, "B1", "Template: %q"), nil, "always")
+	since110 := NewMessage(NewMessageType(Error, "B2", "Template: %q", WithMinVersion("1.10")), nil, "1.10")
+	since111 := NewMessage(NewMessageType(Error, "B3", "Template: %q", WithMinVersion("1.11.2")), nil, "1.11.2")
+	invalid := NewMessage(NewMessageType(Error, "B4", "Template: %q", WithMinVersion("next")), nil, "invalid")
+
+	msgs := Messages{always, since110, since111, invalid}
+
+	for _, c := range []struct {
+		version  string
+		expected Messages
+	}{
+		{"1.9.8", Messages{always, invalid}},
+		{"1.10", Messages{always, since110, invalid}},
+		// Pre-release builds count as the version they precede
+		{"1.10.0-beta.1", Messages{always, since110, invalid}},
+		{"1.11.1", Messages{always, since110, invalid}},
+		{"1.11.2", Messages{always, since110, since111, invalid}},
+		{"2.0.0", Messages{always, since110, since111, invalid}},
+	} {
+		filtered, err := msgs.FilterByVersion(c.version)
+		g.Expect(err).To(BeNil())
+		g.Expect(filtered).To(Equal(c.expected), c.version)
+	}
+
+	_, err := msgs.FilterByVersion("latest")
+	g.Expect(err).To(MatchError(ContainSubstring(`invalid Istio version "latest"`)))
+}
+
+func TestMessages_Affected(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	clusterScoped := &resource.Instance{
+		Metadata: resource.Metadata{FullName: resource.NewShortOrFullName("", "mesh-config")},
+		Origin:   testOrigin{name: "mesh-config"},
+	}
+
+	msgs := Messages{
+		NewMessage(mt, mockSchemaResource("prod", "reviews"), "a"),
+		NewMessage(wt, mockSchemaResource("prod", "reviews"), "b"),
+		NewMessage(mt, mockSchemaResource("prod", "ratings"), "c"),
+		NewMessage(mt, mockSchemaResource("staging", "details"), "d"),
+		NewMessage(mt, clusterScoped, "e"),
+		NewMessage(wt, clusterScoped, "f"),
+		NewMessage(mt, nil, "g"),
+	}
+
+	g.Expect(msgs.AffectedResources()).To(Equal(4))
+	g.Expect(msgs.AffectedNamespaces()).To(Equal(2))
+
+	var empty Messages
+	g.Expect(empty.AffectedResources()).To(BeZero())
+	g.Expect(empty.AffectedNamespaces()).To(BeZero())
+}
+
+func TestMessages_FilterByAnalyzer(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	first := NewMessage(mt, nil, "a")
+	first.Analyzer = "gateway.SecretAnalyzer"
+	second := NewMessage(mt, nil, "b")
+	second.Analyzer = "virtualservice.GatewayAnalyzer"
+	unattributed := NewMessage(mt, nil, "c")
+
+	msgs := Messages{first, second, unattributed}
+	g.Expect(msgs.FilterByAnalyzer("gateway.SecretAnalyzer")).To(Equal(Messages{first}))
+	g.Expect(msgs.FilterByAnalyzer("unknown")).To(BeEmpty())
+	g.Expect(msgs.FilterByAnalyzer("")).To(BeEmpty())
+}
+
+func TestMessages_WithPosition(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	fromFile := NewMessage(mt, &resource.Instance{Origin: testOrigin{name: "a", ref: testReference{"path/to/file:12"}}}, "a")
+	withLine := NewMessage(mt, &resource.Instance{Origin: testOrigin{name: "b", ref: testReference{"path/to/file"}}}, "b")
+	withLine.Line = 3
+	withoutLine := NewMessage(mt, &resource.Instance{Origin: testOrigin{name: "c", ref: testReference{"path/to/file"}}}, "c")
+	fromCluster := NewMessage(mt, MockResource("d"), "d")
+	orphan := NewMessage(mt, nil, "e")
+
+	msgs := Messages{fromFile, withLine, withoutLine, fromCluster, orphan}
+	g.Expect(msgs.WithPosition()).To(Equal(Messages{fromFile, withLine}))
+	g.Expect(msgs.WithoutPosition()).To(Equal(Messages{withoutLine, fromCluster, orphan}))
+}
+
+func TestMessages_ValidateOrigins(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+
+	msgs := Messages{NewMessage(mt, MockResource("SoapBubble"), "a")}
+	g.Expect(msgs.ValidateOrigins()).To(Succeed())
+
+	msgs.Add(
+		NewMessage(wt, nil, "b"),
+		NewMessage(mt, &resource.Instance{}, "c"),
+		NewMessage(wt, nil, "d"),
+	)
+	g.Expect(msgs.ValidateOrigins()).To(MatchError("messages with codes B1, C1 have no resource origin"))
+
+	var empty Messages
+	g.Expect(empty.ValidateOrigins()).To(Succeed())
+}
+
+func TestMessages_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "TEST0192", "Template: %q")
+	g.Expect(RegisterMessageType(mt)).To(Succeed())
+	t.Cleanup(func() { unregisterMessageType("TEST0192") })
+
+	msgs := Messages{NewMessage(mt, MockResource("SoapBubble"), "a")}
+	g.Expect(msgs.Validate()).To(Succeed())
+
+	adjusted := msgs.OverrideLevels(map[string]Level{"TEST0192": Warning})
+	g.Expect(adjusted[0].Type).NotTo(BeIdenticalTo(mt))
+	g.Expect(adjusted.Validate()).To(Succeed())
+
+	var empty Messages
+	g.Expect(empty.Validate()).To(Succeed())
+
+	unregistered := Messages{msgs[0], NewMessage(NewMessageType(Error, "TEST0193", "Template: %q"), nil, "b")}
+	g.Expect(unregistered.Validate()).To(MatchError(`message at index 1 has code "TEST0193", which is not registered`))
+
+	impostor := Messages{NewMessage(NewMessageType(Error, "TEST0192", "Impostor: %q"), nil, "c")}
+	g.Expect(impostor.Validate()).To(MatchError(
+		`message at index 0 has code "TEST0192", which is registered with a different template`))
+
+	untyped := Messages{msgs[0], {}}
+	g.Expect(untyped.Validate()).To(MatchError("message at index 1 has no message type"))
+}
+
+func TestMessages_CapPerCode(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+
+	msgs := Messages{
+		NewMessage(wt, MockResource("r3"), "w3"),
+		NewMessage(mt, MockResource("r2"), "e2"),
+		NewMessage(wt, MockResource("r1"), "w1"),
+		NewMessage(mt, MockResource("r3"), "e3"),
+		NewMessage(wt, MockResource("r2"), "w2"),
+		NewMessage(mt, MockResource("r1"), "e1"),
+		NewMessage(wt, MockResource("r4"), "w4"),
+	}
+
+	capped, dropped := msgs.CapPerCode(2)
+	g.Expect(capped).To(Equal(Messages{msgs[5], msgs[1], msgs[2], msgs[4]}))
+	g.Expect(dropped).To(Equal(map[string]int{"B1": 1, "C1": 2}))
+
+	// The input is left as it was
+	g.Expect(msgs[0].Parameters).To(Equal([]interface{}{"w3"}))
+
+	capped, dropped = msgs.CapPerCode(0)
+	g.Expect(capped).To(HaveLen(len(msgs)))
+	g.Expect(dropped).To(BeEmpty())
+}
+
+func TestMessages_ActionableOnly(t *testing.T) {
+	g := NewWithT(t)
+
+	info := NewMessage(NewMessageType(Info, "B1", "Template: %q"), nil, "info")
+	warning := NewMessage(NewMessageType(Warning, "B2", "Template: %q"), nil, "warning")
+	err := NewMessage(NewMessageType(Error, "B3", "Template: %q"), nil, "error")
+
+	msgs := Messages{info, warning, err, info}
+	g.Expect(msgs.ActionableOnly()).To(Equal(Messages{warning, err}))
+
+	var empty Messages
+	g.Expect(empty.ActionableOnly()).To(BeEmpty())
+}
+
+func TestMessages_OnlyWithSuggestions(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	fixable := NewMessage(mt, nil, "a")
+	fixable.SuggestedFix = &SuggestedFix{Description: "Remove the port", Patch: `[{"op":"remove","path":"/spec/port"}]`}
+
+	msgs := Messages{NewMessage(mt, nil, "b"), fixable, NewMessage(mt, nil, "c")}
+	g.Expect(msgs.OnlyWithSuggestions()).To(Equal(Messages{fixable}))
+
+	var empty Messages
+	g.Expect(empty.OnlyWithSuggestions()).To(BeEmpty())
+}
+
+func TestMessages_FilterByConfidence(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	low := NewMessage(mt, nil, "low")
+	low.Confidence = LowConfidence
+	medium := NewMessage(mt, nil, "medium")
+	medium.Confidence = MediumConfidence
+	unset := NewMessage(mt, nil, "unset")
+
+	msgs := Messages{low, medium, unset}
+	g.Expect(msgs.FilterByConfidence(LowConfidence)).To(Equal(msgs))
+	g.Expect(msgs.FilterByConfidence(MediumConfidence)).To(Equal(Messages{medium, unset}))
+	g.Expect(msgs.FilterByConfidence(HighConfidence)).To(Equal(Messages{unset}))
+}
+
+func TestMessages_StringWithSummary(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	msgs := Messages{
+		NewMessage(wt, MockResource("A"), "w"),
+		NewMessage(mt, MockResource("B"), "e"),
+		NewMessage(wt, nil, "orphan"),
+	}
+
+	g.Expect(msgs.Summary()).To(Equal("Found 3 message(s): 1 Error, 2 Warning, 0 Info."))
+	g.Expect(msgs.StringWithSummary(true)).To(Equal(
+		"Warning [C1] (A) Template: \"w\"\n" +
+			"Error [B1] (B) Template: \"e\"\n" +
+			"Warning [C1] Template: \"orphan\"\n" +
+			"Found 3 message(s): 1 Error, 2 Warning, 0 Info."))
+	g.Expect(msgs.StringWithSummary(false)).To(Equal(
+		"Warning [C1] (A) Template: \"w\"\n" +
+			"Error [B1] (B) Template: \"e\"\n" +
+			"Warning [C1] Template: \"orphan\""))
+
+	var empty Messages
+	g.Expect(empty.StringWithSummary(true)).To(Equal("Found no messages."))
+	g.Expect(empty.StringWithSummary(false)).To(BeEmpty())
+}
+
+func TestMessages_WorstLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	it := NewMessageType(Info, "A1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	mt := NewMessageType(Error, "B1", "Template: %q")
+
+	msgs := Messages{NewMessage(it, nil, "a"), NewMessage(wt, nil, "b")}
+	l, ok := msgs.WorstLevel()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(l).To(Equal(Warning))
+
+	msgs.Add(NewMessage(mt, nil, "c"), NewMessage(it, nil, "d"))
+	l, ok = msgs.WorstLevel()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(l).To(Equal(Error))
+
+	var empty Messages
+	_, ok = empty.WorstLevel()
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestMessages_RenderWithBudget(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	msgs := Messages{
+		NewMessage(mt, MockResource("C"), "c"),
+		NewMessage(mt, MockResource("A"), "a"),
+		NewMessage(mt, MockResource("B"), "b"),
+	}
+	a := `Error [B1] (A) Template: "a"`
+	b := `Error [B1] (B) Template: "b"`
+	c := `Error [B1] (C) Template: "c"`
+	all := a + "\n" + b + "\n" + c
+
+	g.Expect(msgs.RenderWithBudget(0)).To(Equal(all))
+	g.Expect(msgs.RenderWithBudget(len(all))).To(Equal(all))
+
+	g.Expect(msgs.RenderWithBudget(len(all) - 1)).To(Equal(a + "\n" + b + "\n... 1 more omitted"))
+	// The second message doesn't fit along with the note, so it is left out too
+	g.Expect(msgs.RenderWithBudget(len(a+"\n"+b+"\n... 1 more omitted") - 1)).To(Equal(a + "\n... 2 more omitted"))
+	g.Expect(msgs.RenderWithBudget(len(a + "\n... 2 more omitted"))).To(Equal(a + "\n... 2 more omitted"))
+	g.Expect(msgs.RenderWithBudget(len(a+"\n... 2 more omitted") - 1)).To(Equal("... 3 more omitted"))
+	g.Expect(msgs.RenderWithBudget(len("... 3 more omitted") - 1)).To(BeEmpty())
+
+	var empty Messages
+	g.Expect(empty.RenderWithBudget(10)).To(BeEmpty())
+}
@@ -0,0 +1,51 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"time"
+)
+
+// RunMetadata describes the analysis run that produced a set of messages, so that serialized output is
+// self-describing and can be compared with the output of other runs. It is set by the caller, as only the caller
+// knows which version and cluster it ran against.
+//
+// The metadata is serialized under the "metadata" key: at the top level of the JSON and YAML output, alongside the
+// messages, and in the property bag of the run in SARIF output. Empty fields are left out.
+type RunMetadata struct {
+	// IstioVersion is the version of Istio that performed the analysis.
+	IstioVersion string
+	// Timestamp is when the analysis was run.
+	Timestamp time.Time
+	// Cluster identifies the cluster that was analyzed, such as the name of a kubeconfig context. It is empty if the
+	// analysis only covered files.
+	Cluster string
+}
+
+// Unstructured returns the metadata as a map for serialization, leaving out empty fields. The timestamp is formatted
+// as RFC 3339 in UTC.
+func (md *RunMetadata) Unstructured() map[string]interface{} {
+	result := make(map[string]interface{})
+	if md.IstioVersion != "" {
+		result["istioVersion"] = md.IstioVersion
+	}
+	if !md.Timestamp.IsZero() {
+		result["timestamp"] = md.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if md.Cluster != "" {
+		result["cluster"] = md.Cluster
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRunMetadata_Unstructured(t *testing.T) {
+	g := NewWithT(t)
+
+	md := RunMetadata{
+		IstioVersion: "1.9.0",
+		Timestamp:    time.Date(2021, 2, 3, 4, 5, 6, 0, time.FixedZone("test", 3600)),
+		Cluster:      "kind-istio",
+	}
+	g.Expect(md.Unstructured()).To(Equal(map[string]interface{}{
+		"istioVersion": "1.9.0",
+		"timestamp":    "2021-02-03T03:05:06Z",
+		"cluster":      "kind-istio",
+	}))
+}
+
+func TestRunMetadata_UnstructuredOmitsEmpty(t *testing.T) {
+	g := NewWithT(t)
+
+	md := RunMetadata{IstioVersion: "1.9.0"}
+	g.Expect(md.Unstructured()).To(Equal(map[string]interface{}{"istioVersion": "1.9.0"}))
+}
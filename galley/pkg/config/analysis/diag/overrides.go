@@ -0,0 +1,145 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Override replaces metadata of a registered message type, e.g. so that operators can point the documentation of a
+// message at their own runbook without rebuilding. Fields left empty keep the value of the message type.
+type Override struct {
+	Description string
+	URL         string
+	Level       Level
+}
+
+// overrideFile is the on-disk representation of an Override.
+type overrideFile struct {
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Level       string `json:"level,omitempty"`
+}
+
+// ParseOverrides parses overrides from YAML mapping message codes to the fields they replace, e.g.
+//
+//	IST0101:
+//	  description: "A resource being referenced does not exist. See the team runbook."
+//	  url: "https://wiki.example.com/runbooks/ist0101"
+//	  level: Warning
+//
+// Level names are case insensitive.
+func ParseOverrides(data []byte) (map[string]Override, error) {
+	var f map[string]overrideFile
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid overrides: %v", err)
+	}
+
+	overrides := make(map[string]Override, len(f))
+	for code, o := range f {
+		override := Override{Description: o.Description, URL: o.URL}
+		if o.Level != "" {
+			l, err := parseLevel(o.Level)
+			if err != nil {
+				return nil, fmt.Errorf("invalid override for %s: %v", code, err)
+			}
+			override.Level = l
+		}
+		overrides[code] = override
+	}
+	return overrides, nil
+}
+
+// overridden records the original metadata of the message types changed by ApplyOverrides, keyed by code, so that
+// RestoreOverrides can undo the changes.
+var overridden = struct {
+	sync.Mutex
+	originals map[string]overriddenType
+}{
+	originals: make(map[string]overriddenType),
+}
+
+type overriddenType struct {
+	mt          *MessageType
+	description string
+	url         string
+	level       Level
+}
+
+// LoadOverrides reads and parses overrides from a YAML file.
+func LoadOverrides(path string) (map[string]Override, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOverrides(data)
+}
+
+// ApplyOverrides replaces the metadata of registered message types, keyed by code, in place. Overrides take precedence
+// over the values the message types were created with, including those generated from messages.yaml, and the last
+// call to override a field wins. Levels set by a Policy are applied to messages when they are reported, so take
+// precedence over both. Overrides for codes that aren't registered are ignored, and their codes returned in order, so
+// that callers can warn about them.
+//
+// The registered message types are modified rather than copied, because analyzers report messages using the message
+// types they were built with, e.g. the variables in the msg package. The changes are therefore visible to every message
+// created from those types, including ones created earlier, so this should be called at startup, before any analysis
+// runs; it must not be called concurrently with the use of the message types. RestoreOverrides undoes the changes.
+func ApplyOverrides(overrides map[string]Override) []string {
+	overridden.Lock()
+	defer overridden.Unlock()
+
+	var unknown []string
+	for code, o := range overrides {
+		mt, ok := LookupMessageType(code)
+		if !ok {
+			unknown = append(unknown, code)
+			continue
+		}
+		if _, ok := overridden.originals[code]; !ok {
+			overridden.originals[code] = overriddenType{mt: mt, description: mt.description, url: mt.url, level: mt.level}
+		}
+		if o.Description != "" {
+			mt.description = o.Description
+		}
+		if o.URL != "" {
+			mt.url = o.URL
+		}
+		if o.Level != (Level{}) {
+			mt.level = o.Level
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// RestoreOverrides restores the metadata that message types had before the first call to ApplyOverrides that changed
+// them. Like ApplyOverrides, it must not be called concurrently with the use of the message types.
+func RestoreOverrides() {
+	overridden.Lock()
+	defer overridden.Unlock()
+
+	for code, o := range overridden.originals {
+		o.mt.description = o.description
+		o.mt.url = o.url
+		o.mt.level = o.level
+		delete(overridden.originals, code)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseOverrides(t *testing.T) {
+	g := NewWithT(t)
+
+	overrides, err := ParseOverrides([]byte(`
+OVR0001:
+  description: Overridden description
+  url: https://wiki.example.com/ovr0001
+  level: info
+OVR0002:
+  url: https://wiki.example.com/ovr0002
+`))
+	g.Expect(err).To(BeNil())
+	g.Expect(overrides).To(Equal(map[string]Override{
+		"OVR0001": {Description: "Overridden description", URL: "https://wiki.example.com/ovr0001", Level: Info},
+		"OVR0002": {URL: "https://wiki.example.com/ovr0002"},
+	}))
+
+	_, err = ParseOverrides([]byte("OVR0001:\n  level: catastrophic\n"))
+	g.Expect(err).To(MatchError(ContainSubstring("invalid override for OVR0001")))
+
+	_, err = ParseOverrides([]byte("OVR0001:\n  template: Reworded\n"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestLoadOverrides(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	g.Expect(os.WriteFile(path, []byte("OVR0001:\n  level: Error\n"), 0o644)).To(Succeed())
+
+	overrides, err := LoadOverrides(path)
+	g.Expect(err).To(BeNil())
+	g.Expect(overrides).To(Equal(map[string]Override{"OVR0001": {Level: Error}}))
+
+	_, err = LoadOverrides(filepath.Join(t.TempDir(), "missing.yaml"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestApplyOverrides(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Warning, "OVR0003", "Template: %q",
+		WithDescription("Generated description"), WithURL("https://istio.io/ovr0003"))
+	g.Expect(RegisterMessageType(mt)).To(Succeed())
+	t.Cleanup(func() { unregisterMessageType("OVR0003") })
+	t.Cleanup(RestoreOverrides)
+	m := NewMessage(mt, nil, "a")
+
+	unknown := ApplyOverrides(map[string]Override{
+		"OVR0003": {Description: "Overridden description", Level: Error},
+		"OVR9999": {URL: "https://wiki.example.com/ovr9999"},
+		"OVR8888": {URL: "https://wiki.example.com/ovr8888"},
+	})
+	g.Expect(unknown).To(Equal([]string{"OVR8888", "OVR9999"}))
+
+	g.Expect(mt.Description()).To(Equal("Overridden description"))
+	g.Expect(mt.URL()).To(Equal("https://istio.io/ovr0003"))
+	g.Expect(mt.Level()).To(Equal(Error))
+
+	// Messages already created share the message type, so see the overrides too
+	g.Expect(m.String()).To(Equal(`Error [OVR0003] Template: "a"`))
+	ApplyOverrides(map[string]Override{"OVR0003": {URL: "https://wiki.example.com/runbooks/ovr0003"}})
+	g.Expect(m.Unstructured(false)["documentationUrl"]).To(Equal("https://wiki.example.com/runbooks/ovr0003"))
+	m.DocRef = "istioctl-analyze"
+	g.Expect(m.Unstructured(false)["documentationUrl"]).To(Equal("https://wiki.example.com/runbooks/ovr0003?ref=istioctl-analyze"))
+
+	// Restoring returns the values from before the first override, even after several
+	RestoreOverrides()
+	g.Expect(mt.Description()).To(Equal("Generated description"))
+	g.Expect(mt.URL()).To(Equal("https://istio.io/ovr0003"))
+	g.Expect(mt.Level()).To(Equal(Warning))
+	g.Expect(m.String()).To(Equal(`Warning [OVR0003] Template: "a"`))
+}
@@ -0,0 +1,199 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Policy controls how a set of messages is adjusted before being reported. See ApplyPolicy for the order in which
+// the parts of a policy are applied.
+type Policy struct {
+	// Disabled lists message codes that are dropped entirely
+	Disabled []string
+
+	// LevelOverrides maps message codes to the level they are reported at
+	LevelOverrides map[string]Level
+
+	// Escalations raise the level of a message code when it is reported too often
+	Escalations []Escalation
+
+	// Baseline lists fingerprints of known messages that are suppressed
+	Baseline []string
+}
+
+// Escalation raises every message with the given code to at least Level once Threshold or more of them are reported.
+type Escalation struct {
+	Code      string
+	Threshold int
+	Level     Level
+}
+
+// policyFile is the on-disk representation of a Policy.
+type policyFile struct {
+	Disabled       []string          `json:"disabled,omitempty"`
+	LevelOverrides map[string]string `json:"levelOverrides,omitempty"`
+	Escalations    []escalationFile  `json:"escalations,omitempty"`
+	Baseline       []string          `json:"baseline,omitempty"`
+}
+
+type escalationFile struct {
+	Code      string `json:"code"`
+	Threshold int    `json:"threshold"`
+	Level     string `json:"level"`
+}
+
+// ParsePolicy parses a Policy from YAML. Level names are case insensitive.
+func ParsePolicy(data []byte) (Policy, error) {
+	var f policyFile
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return Policy{}, fmt.Errorf("invalid policy: %v", err)
+	}
+
+	p := Policy{
+		Disabled: f.Disabled,
+		Baseline: f.Baseline,
+	}
+	if len(f.LevelOverrides) > 0 {
+		p.LevelOverrides = make(map[string]Level, len(f.LevelOverrides))
+		for code, name := range f.LevelOverrides {
+			l, err := parseLevel(name)
+			if err != nil {
+				return Policy{}, fmt.Errorf("invalid level override for %s: %v", code, err)
+			}
+			p.LevelOverrides[code] = l
+		}
+	}
+	for _, e := range f.Escalations {
+		if e.Code == "" {
+			return Policy{}, fmt.Errorf("invalid escalation: missing code")
+		}
+		if e.Threshold < 1 {
+			return Policy{}, fmt.Errorf("invalid escalation for %s: threshold must be at least 1, got %d", e.Code, e.Threshold)
+		}
+		l, err := parseLevel(e.Level)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid escalation for %s: %v", e.Code, err)
+		}
+		p.Escalations = append(p.Escalations, Escalation{Code: e.Code, Threshold: e.Threshold, Level: l})
+	}
+	return p, nil
+}
+
+// LoadPolicy reads and parses a Policy from a YAML file.
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	return ParsePolicy(data)
+}
+
+func parseLevel(name string) (Level, error) {
+	l, ok := GetUppercaseStringToLevelMap()[strings.ToUpper(name)]
+	if !ok {
+		return Level{}, fmt.Errorf("%q is not a valid level, expected one of %v", name, GetAllLevelStrings())
+	}
+	return l, nil
+}
+
+// ApplyPolicy applies a Policy to a set of messages, returning the result. The parts of the policy are applied in
+// this order:
+//
+//  1. Disabled codes are dropped.
+//  2. Level overrides are applied.
+//  3. Escalations are applied, counting messages after overrides.
+//  4. Messages in the baseline are suppressed. Suppressed messages still count towards escalation.
+func ApplyPolicy(ms Messages, p Policy) Messages {
+	result := ms.Disable(p.Disabled...)
+	result = result.OverrideLevels(p.LevelOverrides)
+	result = result.Escalate(p.Escalations...)
+	return result.SuppressBaseline(p.Baseline...)
+}
+
+// Disable returns the messages whose code is not one of the given codes.
+func (ms *Messages) Disable(codes ...string) Messages {
+	disabled := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		disabled[c] = true
+	}
+
+	var result Messages
+	for _, m := range *ms {
+		if !disabled[m.Type.Code()] {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// OverrideLevels returns a copy of the messages with the level of each code in overrides replaced.
+func (ms *Messages) OverrideLevels(overrides map[string]Level) Messages {
+	result := make(Messages, 0, len(*ms))
+	for _, m := range *ms {
+		if l, ok := overrides[m.Type.Code()]; ok {
+			m.Type = m.Type.withLevel(l)
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// Escalate returns a copy of the messages where, for each escalation whose code is reported at least its threshold
+// number of times, messages with that code are raised to at least the escalation's level. Levels are never lowered.
+func (ms *Messages) Escalate(escalations ...Escalation) Messages {
+	counts := ms.CountsByCode()
+	result := make(Messages, 0, len(*ms))
+	for _, m := range *ms {
+		for _, e := range escalations {
+			if e.Code == m.Type.Code() && counts[e.Code] >= e.Threshold && !m.Type.Level().IsWorseThanOrEqualTo(e.Level) {
+				m.Type = m.Type.withLevel(e.Level)
+			}
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// SuppressBaseline returns the messages whose fingerprint is not one of the given fingerprints.
+func (ms *Messages) SuppressBaseline(fingerprints ...string) Messages {
+	baseline := make(map[string]bool, len(fingerprints))
+	for _, fp := range fingerprints {
+		baseline[fp] = true
+	}
+
+	var result Messages
+	for _, m := range *ms {
+		if !baseline[m.Fingerprint()] {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// withLevel returns a copy of the MessageType at the given level. The original is left untouched, since message types
+// are shared between every message of that type.
+func (m *MessageType) withLevel(l Level) *MessageType {
+	if m.level == l {
+		return m
+	}
+	c := *m
+	c.level = l
+	return &c
+}
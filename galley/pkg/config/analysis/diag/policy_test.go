@@ -0,0 +1,173 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+var (
+	policyTypeA = NewMessageType(Info, "IST-A", "Template: %q")
+	policyTypeB = NewMessageType(Warning, "IST-B", "Template: %q")
+)
+
+func codesAndLevels(ms Messages) []string {
+	var result []string
+	for _, m := range ms {
+		result = append(result, m.Type.Code()+"="+m.Type.Level().String())
+	}
+	return result
+}
+
+func TestMessages_Disable(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := Messages{
+		NewMessage(policyTypeA, MockResource("a"), "a"),
+		NewMessage(policyTypeB, MockResource("b"), "b"),
+	}
+	g.Expect(codesAndLevels(ms.Disable("IST-A"))).To(Equal([]string{"IST-B=Warning"}))
+	g.Expect(ms.Disable()).To(Equal(ms))
+}
+
+func TestMessages_OverrideLevels(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := Messages{
+		NewMessage(policyTypeA, MockResource("a"), "a"),
+		NewMessage(policyTypeB, MockResource("b"), "b"),
+	}
+	g.Expect(codesAndLevels(ms.OverrideLevels(map[string]Level{"IST-A": Error}))).
+		To(Equal([]string{"IST-A=Error", "IST-B=Warning"}))
+
+	// The shared message type must not be modified
+	g.Expect(policyTypeA.Level()).To(Equal(Info))
+}
+
+func TestMessages_Escalate(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := Messages{
+		NewMessage(policyTypeA, MockResource("a1"), "a1"),
+		NewMessage(policyTypeA, MockResource("a2"), "a2"),
+		NewMessage(policyTypeB, MockResource("b"), "b"),
+	}
+
+	g.Expect(codesAndLevels(ms.Escalate(
+		Escalation{Code: "IST-A", Threshold: 2, Level: Warning},
+		Escalation{Code: "IST-B", Threshold: 2, Level: Error},
+	))).To(Equal([]string{"IST-A=Warning", "IST-A=Warning", "IST-B=Warning"}))
+
+	// Escalations never lower a level
+	g.Expect(codesAndLevels(ms.Escalate(Escalation{Code: "IST-B", Threshold: 1, Level: Info}))).
+		To(Equal([]string{"IST-A=Info", "IST-A=Info", "IST-B=Warning"}))
+}
+
+func TestMessages_SuppressBaseline(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewMessage(policyTypeA, MockResource("a"), "a")
+	b := NewMessage(policyTypeB, MockResource("b"), "b")
+	ms := Messages{a, b}
+
+	g.Expect(ms.SuppressBaseline(a.Fingerprint())).To(Equal(Messages{b}))
+	g.Expect(ms.SuppressBaseline("unknown")).To(Equal(ms))
+}
+
+func TestApplyPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	typeC := NewMessageType(Info, "IST-C", "Template: %q")
+	a1 := NewMessage(policyTypeA, MockResource("a1"), "a1")
+	a2 := NewMessage(policyTypeA, MockResource("a2"), "a2")
+	b := NewMessage(policyTypeB, MockResource("b"), "b")
+	c := NewMessage(typeC, MockResource("c"), "c")
+
+	p := Policy{
+		Disabled:       []string{"IST-C"},
+		LevelOverrides: map[string]Level{"IST-B": Info},
+		Escalations: []Escalation{
+			// Only matches once IST-B has been overridden to Info
+			{Code: "IST-B", Threshold: 1, Level: Warning},
+			// Counts a1 even though it is suppressed below
+			{Code: "IST-A", Threshold: 2, Level: Error},
+		},
+		Baseline: []string{a1.Fingerprint(), c.Fingerprint()},
+	}
+
+	g.Expect(codesAndLevels(ApplyPolicy(Messages{a1, a2, b, c}, p))).
+		To(Equal([]string{"IST-A=Error", "IST-B=Warning"}))
+	g.Expect(ApplyPolicy(Messages{a1, a2, b, c}, Policy{})).To(Equal(Messages{a1, a2, b, c}))
+}
+
+func TestParsePolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	p, err := ParsePolicy([]byte(`
+disabled: [IST0102]
+levelOverrides:
+  IST0101: warning
+escalations:
+- code: IST0103
+  threshold: 5
+  level: Error
+baseline: [abc123]
+`))
+	g.Expect(err).To(BeNil())
+	g.Expect(p).To(Equal(Policy{
+		Disabled:       []string{"IST0102"},
+		LevelOverrides: map[string]Level{"IST0101": Warning},
+		Escalations:    []Escalation{{Code: "IST0103", Threshold: 5, Level: Error}},
+		Baseline:       []string{"abc123"},
+	}))
+
+	p, err = ParsePolicy([]byte(""))
+	g.Expect(err).To(BeNil())
+	g.Expect(p).To(Equal(Policy{}))
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"unknownField: true",
+		"levelOverrides: {IST0101: bogus}",
+		"escalations: [{code: IST0101, threshold: 0, level: Error}]",
+		"escalations: [{threshold: 1, level: Error}]",
+		"escalations: [{code: IST0101, threshold: 1, level: bogus}]",
+	} {
+		t.Run(input, func(t *testing.T) {
+			g := NewWithT(t)
+			_, err := ParsePolicy([]byte(input))
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	g.Expect(os.WriteFile(path, []byte("disabled: [IST0102]"), 0o644)).To(Succeed())
+
+	p, err := LoadPolicy(path)
+	g.Expect(err).To(BeNil())
+	g.Expect(p.Disabled).To(Equal([]string{"IST0102"}))
+
+	_, err = LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	g.Expect(err).To(HaveOccurred())
+}
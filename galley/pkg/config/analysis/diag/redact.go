@@ -0,0 +1,96 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// RedactedText replaces redacted content in messages.
+const RedactedText = "***"
+
+// redactedValue is a parameter value that has been (partially) redacted. It formats as its string value regardless
+// of the verb used in the template, so that redacting a non-string parameter doesn't produce a formatting error.
+type redactedValue string
+
+var _ fmt.Formatter = redactedValue("")
+
+// Format implements fmt.Formatter
+func (r redactedValue) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		_, _ = io.WriteString(f, strconv.Quote(string(r)))
+		return
+	}
+	_, _ = io.WriteString(f, string(r))
+}
+
+// Redact returns a copy of the message in which every match of the pattern within the formatted parameters is
+// replaced with RedactedText. Redaction is applied to each parameter separately rather than to the rendered text, so
+// the fixed text of the template is never redacted.
+func (m Message) Redact(pattern *regexp.Regexp) Message {
+	params := make([]interface{}, len(m.Parameters))
+	for i, p := range m.Parameters {
+		s := fmt.Sprint(p)
+		if r := pattern.ReplaceAllString(s, RedactedText); r != s {
+			params[i] = redactedValue(r)
+		} else {
+			params[i] = p
+		}
+	}
+	m.Parameters = params
+	m.text = &renderedText{}
+	return m
+}
+
+// RedactArgs returns a copy of the message in which the parameters for the named arguments of its message type, as
+// returned by MessageType.Args, are replaced entirely with RedactedText, e.g. to hide hosts from shared output.
+// Names that aren't arguments of the message type are ignored.
+func (m Message) RedactArgs(names ...string) Message {
+	redact := make(map[string]bool, len(names))
+	for _, n := range names {
+		redact[n] = true
+	}
+
+	params := append([]interface{}(nil), m.Parameters...)
+	for i, name := range m.Type.Args() {
+		if i < len(params) && redact[name] {
+			params[i] = redactedValue(RedactedText)
+		}
+	}
+	m.Parameters = params
+	m.text = &renderedText{}
+	return m
+}
+
+// Redact returns a copy of the messages with Message.Redact applied to each.
+func (ms *Messages) Redact(pattern *regexp.Regexp) Messages {
+	result := make(Messages, 0, len(*ms))
+	for _, m := range *ms {
+		result = append(result, m.Redact(pattern))
+	}
+	return result
+}
+
+// RedactArgs returns a copy of the messages with Message.RedactArgs applied to each.
+func (ms *Messages) RedactArgs(names ...string) Messages {
+	result := make(Messages, 0, len(*ms))
+	for _, m := range *ms {
+		result = append(result, m.RedactArgs(names...))
+	}
+	return result
+}
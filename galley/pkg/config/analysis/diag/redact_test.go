@@ -0,0 +1,96 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage_Redact(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Host %q on port %d failed: %v")
+	m := NewMessage(mt, nil, "db.internal.example.com", 5432, errors.New("secret token abc123 rejected"))
+
+	redacted := m.Redact(regexp.MustCompile(`internal\.example\.com|abc\d+|5432`))
+	g.Expect(redacted.String()).To(Equal(`Error [IST0042] Host "db.***" on port *** failed: secret token *** rejected`))
+
+	// The original message is left untouched
+	g.Expect(m.String()).To(Equal(`Error [IST0042] Host "db.internal.example.com" on port 5432 failed: secret token abc123 rejected`))
+}
+
+func TestMessage_RedactLeavesTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Warning, "IST0042", "Port %s is not secret")
+	m := NewMessage(mt, nil, "http")
+
+	redacted := m.Redact(regexp.MustCompile("secret"))
+	g.Expect(redacted.String()).To(Equal("Warning [IST0042] Port http is not secret"))
+}
+
+func TestMessages_Redact(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Hosts: %v")
+	msgs := Messages{
+		NewMessage(mt, nil, []string{"a.internal", "b.public"}),
+		NewMessage(mt, nil, []string{"c.public"}),
+	}
+
+	redacted := msgs.Redact(regexp.MustCompile(`\w+\.internal`))
+	g.Expect(redacted).To(HaveLen(2))
+	g.Expect(redacted[0].String()).To(Equal("Error [IST0042] Hosts: [*** b.public]"))
+	g.Expect(redacted[1].String()).To(Equal("Error [IST0042] Hosts: [c.public]"))
+	g.Expect(msgs[0].String()).To(Equal("Error [IST0042] Hosts: [a.internal b.public]"))
+}
+
+func TestMessage_RedactArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Host {{quote .host}} on port {{.port}} failed: {{.reason}}",
+		WithArgs("host", "port", "reason"))
+	m := NewMessage(mt, nil, "db.internal.example.com", 5432, "timeout")
+
+	redacted := m.RedactArgs("host", "port", "unknown")
+	g.Expect(redacted.String()).To(Equal(`Error [IST0042] Host "***" on port *** failed: timeout`))
+	g.Expect(m.String()).To(Equal(`Error [IST0042] Host "db.internal.example.com" on port 5432 failed: timeout`))
+
+	// Printf templates are redacted by the argument names too
+	printf := NewMessageType(Error, "IST0043", "Host %q on port %d", WithArgs("host", "port"))
+	redacted = NewMessage(printf, nil, "db.internal", 5432).RedactArgs("port")
+	g.Expect(redacted.String()).To(Equal(`Error [IST0043] Host "db.internal" on port ***`))
+
+	// Message types without named arguments are left alone
+	redacted = NewMessage(NewMessageType(Error, "IST0044", "Host %q"), nil, "db.internal").RedactArgs("host")
+	g.Expect(redacted.String()).To(Equal(`Error [IST0044] Host "db.internal"`))
+}
+
+func TestMessages_RedactArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Host %s failed: %s", WithArgs("host", "reason"))
+	msgs := Messages{NewMessage(mt, nil, "a.internal", "timeout"), NewMessage(mt, nil, "b.internal", "refused")}
+
+	redacted := msgs.RedactArgs("host")
+	g.Expect(redacted).To(HaveLen(2))
+	g.Expect(redacted[0].String()).To(Equal("Error [IST0042] Host *** failed: timeout"))
+	g.Expect(redacted[1].String()).To(Equal("Error [IST0042] Host *** failed: refused"))
+	g.Expect(msgs[0].String()).To(Equal("Error [IST0042] Host a.internal failed: timeout"))
+}
@@ -0,0 +1,81 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// registry holds the message types known at runtime, keyed by code. The generated message types of the msg package
+// register themselves at init, and out-of-tree analyzers may register their own with RegisterMessageType.
+var registry = struct {
+	sync.RWMutex
+	types map[string]*MessageType
+}{
+	types: make(map[string]*MessageType),
+}
+
+// RegisterMessageType adds a message type to the runtime registry, making it available to lookups by code. It is an
+// error to register a different message type with the code of one already registered; registering the same message
+// type again has no effect. Message types must declare a level to be registered; see SetFallbackLevel.
+func RegisterMessageType(mt *MessageType) error {
+	if mt.level == (Level{}) {
+		return fmt.Errorf("message type with code %q must declare a level to be registered", mt.Code())
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if existing, ok := registry.types[mt.Code()]; ok {
+		if existing == mt {
+			return nil
+		}
+		return fmt.Errorf("a message type with code %q is already registered", mt.Code())
+	}
+	registry.types[mt.Code()] = mt
+	return nil
+}
+
+// unregisterMessageType removes the message type with the given code from the registry, so that tests can undo their
+// registrations.
+func unregisterMessageType(code string) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.types, code)
+}
+
+// LookupMessageType returns the registered message type with the given code, if any.
+func LookupMessageType(code string) (*MessageType, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	mt, ok := registry.types[code]
+	return mt, ok
+}
+
+// RegisteredMessageTypes returns all registered message types, ordered by code.
+func RegisteredMessageTypes() []*MessageType {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	types := make([]*MessageType, 0, len(registry.types))
+	for _, mt := range registry.types {
+		types = append(types, mt)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Code() < types[j].Code() })
+	return types
+}
@@ -0,0 +1,59 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRegisterMessageType(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Warning, "TEST0001", "Template: %q")
+	g.Expect(RegisterMessageType(mt)).To(Succeed())
+	t.Cleanup(func() { unregisterMessageType("TEST0001") })
+	// Registering the same type again is fine
+	g.Expect(RegisterMessageType(mt)).To(Succeed())
+
+	conflict := NewMessageType(Error, "TEST0001", "Other template: %q")
+	g.Expect(RegisterMessageType(conflict)).To(MatchError(ContainSubstring(`"TEST0001" is already registered`)))
+
+	found, ok := LookupMessageType("TEST0001")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(found).To(BeIdenticalTo(mt))
+
+	_, ok = LookupMessageType("TEST9999")
+	g.Expect(ok).To(BeFalse())
+
+	other := NewMessageType(Warning, "TEST0000", "Template: %q")
+	g.Expect(RegisterMessageType(other)).To(Succeed())
+	t.Cleanup(func() { unregisterMessageType("TEST0000") })
+	types := RegisteredMessageTypes()
+	g.Expect(types).To(ContainElements(mt, other))
+	for i := 1; i < len(types); i++ {
+		g.Expect(types[i-1].Code() < types[i].Code()).To(BeTrue())
+	}
+}
+
+func TestRegisterMessageType_NoLevel(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Level{}, "TEST0002", "Template: %q")
+	g.Expect(RegisterMessageType(mt)).To(MatchError(ContainSubstring("must declare a level")))
+	_, ok := LookupMessageType("TEST0002")
+	g.Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,63 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResourceSummaryFormatter renders one line per resource with messages on it, giving the most severe level of its
+// messages and the number at each level, e.g. "[Error] default/VirtualService/reviews: 2 errors, 1 warning". Lines are
+// sorted by resource, as formatted by Message.ResourceKey, and messages without a resource are summarized on a final
+// line of their own. It is a condensed alternative to listing every message, for scanning many resources.
+type ResourceSummaryFormatter struct{}
+
+var _ Formatter = ResourceSummaryFormatter{}
+
+// Format implements Formatter
+func (f ResourceSummaryFormatter) Format(ms Messages) (string, error) {
+	groups := ms.GroupByResource()
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(groups))
+	for _, key := range keys {
+		group := groups[key]
+		lines = append(lines, resourceSummaryLine(key, &group))
+	}
+	if orphans, ok := groups[""]; ok {
+		lines = append(lines, resourceSummaryLine(treeNoResourceNode, &orphans))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func resourceSummaryLine(resource string, ms *Messages) string {
+	worst, _ := ms.WorstLevel()
+	counts := ms.CountsByLevel()
+	var parts []string
+	for _, l := range []Level{Error, Warning, Info} {
+		if n := counts[l]; n > 0 {
+			parts = append(parts, levelCount(l, n))
+		}
+	}
+	return fmt.Sprintf("[%v] %s: %s", worst, resource, strings.Join(parts, ", "))
+}
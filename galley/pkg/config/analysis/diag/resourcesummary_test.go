@@ -0,0 +1,67 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestResourceSummaryFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	et := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+	it := NewMessageType(Info, "D1", "Template: %q")
+
+	msgs := Messages{
+		NewMessage(wt, mockSchemaResource("prod", "reviews"), "w"),
+		NewMessage(et, mockSchemaResource("prod", "reviews"), "e1"),
+		NewMessage(et, mockSchemaResource("prod", "reviews"), "e2"),
+		NewMessage(it, mockSchemaResource("dev", "ratings"), "i1"),
+		NewMessage(it, mockSchemaResource("dev", "ratings"), "i2"),
+		NewMessage(wt, MockResource("unknown"), "w"),
+		NewMessage(et, nil, "none"),
+	}
+
+	output, err := ResourceSummaryFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"[Warning] default/unknown: 1 warning\n" +
+			"[Info] dev/VirtualService/ratings: 2 info\n" +
+			"[Error] prod/VirtualService/reviews: 2 errors, 1 warning\n" +
+			"[Error] (no resource): 1 error",
+	))
+
+	output, err = ResourceSummaryFormatter{}.Format(nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(BeEmpty())
+}
+
+func TestMessages_GroupByResource(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	first := NewMessage(mt, mockSchemaResource("prod", "reviews"), "b")
+	second := NewMessage(mt, mockSchemaResource("prod", "reviews"), "a")
+	third := NewMessage(mt, nil, "none")
+
+	msgs := Messages{first, second, third}
+	g.Expect(msgs.GroupByResource()).To(Equal(map[string]Messages{
+		"prod/VirtualService/reviews": {second, first},
+		"":                            {third},
+	}))
+}
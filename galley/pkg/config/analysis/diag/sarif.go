@@ -0,0 +1,254 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/url"
+)
+
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "istio-analyze"
+)
+
+// SARIFFormatter renders messages as a SARIF (Static Analysis Results Interchange Format) log, with one rule per
+// distinct message type.
+type SARIFFormatter struct {
+	// IncludeHelp populates the help text and help URL of each rule from the long description, or else the
+	// description, and documentation URL of its message type, so that code scanning UIs can show remediation steps
+	// alongside each result.
+	IncludeHelp bool
+
+	// Metadata, if set, is included in the property bag of the run under the "metadata" key.
+	Metadata *RunMetadata
+}
+
+var _ Formatter = SARIFFormatter{}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool           `json:"tool"`
+	Results    []sarifResult       `json:"results"`
+	Properties *sarifRunProperties `json:"properties,omitempty"`
+}
+
+type sarifRunProperties struct {
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string          `json:"id"`
+	ShortDescription sarifText       `json:"shortDescription"`
+	Help             *sarifText      `json:"help,omitempty"`
+	HelpURI          string          `json:"helpUri,omitempty"`
+	DefaultConfig    sarifRuleConf   `json:"defaultConfiguration"`
+	Properties       *sarifRuleProps `json:"properties,omitempty"`
+}
+
+type sarifRuleProps struct {
+	Autofix bool `json:"autofix"`
+}
+
+type sarifRuleConf struct {
+	Level string `json:"level"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+
+	Properties *sarifResultProperties `json:"properties,omitempty"`
+}
+
+type sarifResultProperties struct {
+	SuggestedFix *SuggestedFix `json:"suggestedFix,omitempty"`
+	Confidence   string        `json:"confidence,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// Format implements Formatter
+func (f SARIFFormatter) Format(ms Messages) (string, error) {
+	sorted := append(ms[:0:0], ms...)
+	sorted.Sort()
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           sarifToolName,
+				InformationURI: url.ConfigAnalysis,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+	if f.Metadata != nil {
+		run.Properties = &sarifRunProperties{Metadata: f.Metadata.Unstructured()}
+	}
+
+	ruleIndexes := make(map[string]int)
+	for _, m := range sorted {
+		idx, ok := ruleIndexes[m.Type.Code()]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndexes[m.Type.Code()] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, f.rule(m.Type))
+		}
+
+		result := sarifResult{
+			RuleID:    m.Type.Code(),
+			RuleIndex: idx,
+			Level:     sarifLevel(m.Type.Level()),
+			Message:   sarifText{Text: m.Text()},
+		}
+		if loc := sarifLocationOf(m.Resource, m.Line); loc != nil {
+			result.Locations = []sarifLocation{*loc}
+		}
+		if m.SuggestedFix != nil || m.Confidence != (Confidence{}) {
+			// SARIF fixes must be expressed as edits to file regions, which aren't known, so the fix is carried in the
+			// property bag of the result instead
+			result.Properties = &sarifResultProperties{SuggestedFix: m.SuggestedFix, Confidence: m.Confidence.String()}
+		}
+		for _, r := range m.Related {
+			if loc := sarifLocationOf(r, 0); loc != nil {
+				result.RelatedLocations = append(result.RelatedLocations, *loc)
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	out, err := json.MarshalIndent(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	return string(out), err
+}
+
+func (f SARIFFormatter) rule(mt *MessageType) sarifRule {
+	short := mt.Description()
+	if short == "" {
+		short = mt.Code()
+	}
+
+	r := sarifRule{
+		ID:               mt.Code(),
+		ShortDescription: sarifText{Text: short},
+		DefaultConfig:    sarifRuleConf{Level: sarifLevel(mt.Level())},
+	}
+	if f.IncludeHelp {
+		help := mt.LongDescription()
+		if help == "" {
+			help = short
+		}
+		r.Help = &sarifText{Text: help}
+		r.HelpURI = mt.URL()
+	}
+	if mt.HasAutofix() {
+		r.Properties = &sarifRuleProps{Autofix: true}
+	}
+	return r
+}
+
+func sarifLevel(l Level) string {
+	switch l {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifLocationOf returns the location of a resource, or nil if it has no origin. If line is non-zero, it overrides
+// the line of the resource reference.
+func sarifLocationOf(r *resource.Instance, line int) *sarifLocation {
+	if r == nil || r.Origin == nil {
+		return nil
+	}
+
+	loc := &sarifLocation{
+		LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: r.Origin.FriendlyName()}},
+	}
+
+	if r.Origin.Reference() == nil {
+		return loc
+	}
+	ref := r.Origin.Reference().String()
+	if ref == "" {
+		return loc
+	}
+
+	path, l := splitReference(ref)
+	if line == 0 {
+		line = l
+	}
+	loc.PhysicalLocation = &sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: path},
+	}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return loc
+}
@@ -0,0 +1,164 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/config/resource"
+)
+
+func TestSARIFFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	documented := NewMessageType(Error, "IST0042", "Cheese type not found: %q",
+		WithDescription("The cheese is missing."), WithURL("https://example.com/ist0042"))
+	undocumented := NewMessageType(Info, "IST0043", "Cracker type not found: %q")
+
+	msgs := Messages{
+		NewMessage(undocumented, nil, "Saltine"),
+		NewMessage(documented, &resource.Instance{Origin: testOrigin{name: "toppings/cheese", ref: testReference{"path/to/file:12"}}}, "Feta"),
+		NewMessage(documented, nil, "Brie"),
+	}
+
+	output, err := SARIFFormatter{IncludeHelp: true}.Format(msgs)
+	g.Expect(err).To(BeNil())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Version).To(Equal(sarifVersion))
+	g.Expect(log.Runs).To(HaveLen(1))
+
+	rules := log.Runs[0].Tool.Driver.Rules
+	g.Expect(rules).To(HaveLen(2))
+	g.Expect(rules[0].ID).To(Equal("IST0042"))
+	g.Expect(rules[0].Help).To(Equal(&sarifText{Text: "The cheese is missing."}))
+	g.Expect(rules[0].HelpURI).To(Equal("https://example.com/ist0042"))
+	g.Expect(rules[1].ID).To(Equal("IST0043"))
+	// Falls back to the short description, which falls back to the code
+	g.Expect(rules[1].Help).To(Equal(&sarifText{Text: "IST0043"}))
+	g.Expect(rules[1].HelpURI).To(BeEmpty())
+
+	results := log.Runs[0].Results
+	g.Expect(results).To(HaveLen(3))
+	g.Expect(results[0].Level).To(Equal("error"))
+	g.Expect(results[0].Message.Text).To(Equal(`Cheese type not found: "Brie"`))
+	g.Expect(results[0].Locations).To(BeEmpty())
+	g.Expect(results[1].RuleIndex).To(Equal(0))
+	g.Expect(results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI).To(Equal("path/to/file"))
+	g.Expect(results[1].Locations[0].PhysicalLocation.Region.StartLine).To(Equal(12))
+	g.Expect(results[2].Level).To(Equal("note"))
+	g.Expect(results[2].RuleIndex).To(Equal(1))
+}
+
+func TestSARIFFormatter_LongDescription(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q",
+		WithDescription("The cheese is missing."),
+		WithLongDescription("The cheese is missing. Add it to the platter, or remove the reference to it."))
+
+	output, err := SARIFFormatter{IncludeHelp: true}.Format(Messages{NewMessage(mt, nil, "Feta")})
+	g.Expect(err).To(BeNil())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	rule := log.Runs[0].Tool.Driver.Rules[0]
+	g.Expect(rule.ShortDescription).To(Equal(sarifText{Text: "The cheese is missing."}))
+	g.Expect(rule.Help).To(Equal(&sarifText{Text: "The cheese is missing. Add it to the platter, or remove the reference to it."}))
+}
+
+func TestSARIFFormatter_WithoutHelp(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q",
+		WithDescription("The cheese is missing."), WithURL("https://example.com/ist0042"))
+
+	output, err := SARIFFormatter{}.Format(Messages{NewMessage(mt, nil, "Feta")})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).NotTo(ContainSubstring("helpUri"))
+	g.Expect(output).To(ContainSubstring(`"shortDescription": {`))
+}
+
+func TestSARIFFormatter_SuggestedFix(t *testing.T) {
+	g := NewWithT(t)
+
+	m := NewMessage(NewMessageType(Error, "IST0042", "Cheese type not found: %q"), nil, "Feta")
+	m.SuggestedFix = &SuggestedFix{Description: "Use Brie", Replacement: "cheese: Brie"}
+
+	output, err := SARIFFormatter{}.Format(Messages{m})
+	g.Expect(err).To(BeNil())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Runs[0].Results[0].Properties.SuggestedFix).To(Equal(m.SuggestedFix))
+}
+
+func TestSARIFFormatter_Confidence(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	m := NewMessage(mt, nil, "Feta")
+	m.Confidence = MediumConfidence
+
+	output, err := SARIFFormatter{}.Format(Messages{m, NewMessage(mt, nil, "Brie")})
+	g.Expect(err).To(BeNil())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Runs[0].Results[0].Properties).To(BeNil())
+	g.Expect(log.Runs[0].Results[1].Properties).To(Equal(&sarifResultProperties{Confidence: "Medium"}))
+}
+
+func TestSARIFFormatter_Autofix(t *testing.T) {
+	g := NewWithT(t)
+
+	fixable := NewMessageType(Error, "IST0042", "Cheese type not found: %q", WithAutofix())
+	unfixable := NewMessageType(Error, "IST0043", "Cracker type not found: %q")
+	g.Expect(fixable.HasAutofix()).To(BeTrue())
+	g.Expect(unfixable.HasAutofix()).To(BeFalse())
+
+	output, err := SARIFFormatter{}.Format(Messages{NewMessage(fixable, nil, "Feta"), NewMessage(unfixable, nil, "Saltine")})
+	g.Expect(err).To(BeNil())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Runs[0].Tool.Driver.Rules[0].Properties).To(Equal(&sarifRuleProps{Autofix: true}))
+	g.Expect(log.Runs[0].Tool.Driver.Rules[1].Properties).To(BeNil())
+}
+
+func TestSARIFFormatter_Metadata(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	msgs := Messages{NewMessage(mt, nil, "Feta")}
+
+	output, err := SARIFFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).NotTo(ContainSubstring(`"properties"`))
+
+	md := &RunMetadata{IstioVersion: "1.9.0", Cluster: "kind-istio"}
+	output, err = SARIFFormatter{Metadata: md}.Format(msgs)
+	g.Expect(err).To(BeNil())
+
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(output), &log)).To(Succeed())
+	g.Expect(log.Runs[0].Properties).To(Equal(&sarifRunProperties{
+		Metadata: map[string]interface{}{"istioVersion": "1.9.0", "cluster": "kind-istio"},
+	}))
+}
@@ -0,0 +1,42 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"log/slog"
+)
+
+// SlogAttrs returns the fields of the message as structured logging attributes, for use with slog.Logger.LogAttrs.
+// The code, level and message have the same values as in the JSON output. The namespace, kind and name of the
+// resource are only included when known.
+func (m *Message) SlogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("code", m.Type.Code()),
+		slog.String("level", m.Type.Level().String()),
+	}
+
+	namespace, kind, name := m.resourceCoordinates()
+	if namespace != "" {
+		attrs = append(attrs, slog.String("namespace", namespace))
+	}
+	if kind != "" {
+		attrs = append(attrs, slog.String("kind", kind))
+	}
+	if name != "" {
+		attrs = append(attrs, slog.String("name", name))
+	}
+
+	return append(attrs, slog.String("message", m.Text()))
+}
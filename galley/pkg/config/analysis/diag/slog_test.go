@@ -0,0 +1,51 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage_SlogAttrs(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	m := NewMessage(mt, mockSchemaResource("cheese", "feta"), "Feta")
+
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.LogAttrs(context.Background(), slog.LevelWarn, "analysis finding", m.SlogAttrs()...)
+	g.Expect(b.String()).To(Equal(`level=WARN msg="analysis finding" code=IST0042 level=Error namespace=cheese ` +
+		`kind=VirtualService name=feta message="Cheese type not found: \"Feta\""` + "\n"))
+
+	unattributed := NewMessage(mt, nil, "Feta")
+	g.Expect(unattributed.SlogAttrs()).To(Equal([]slog.Attr{
+		slog.String("code", "IST0042"),
+		slog.String("level", "Error"),
+		slog.String("message", `Cheese type not found: "Feta"`),
+	}))
+}
@@ -0,0 +1,61 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+// SpanEventName is the name of the span events recorded for messages by RecordSpanEvents.
+const SpanEventName = "istio.analysis.message"
+
+// Attribute keys of the span events recorded for messages by RecordSpanEvents. The resource attribute is left out of
+// events for messages without a resource.
+const (
+	SpanAttributeCode     = "istio.analysis.code"
+	SpanAttributeLevel    = "istio.analysis.level"
+	SpanAttributeResource = "istio.analysis.resource"
+	SpanAttributeText     = "istio.analysis.text"
+)
+
+// SpanEventRecorder records named events with attributes on a trace span. It is satisfied by a small adapter around
+// an OpenTelemetry span, e.g.
+//
+//	type spanRecorder struct{ trace.Span }
+//
+//	func (s spanRecorder) AddEvent(name string, attrs map[string]string) {
+//		var kvs []attribute.KeyValue
+//		for k, v := range attrs {
+//			kvs = append(kvs, attribute.String(k, v))
+//		}
+//		s.Span.AddEvent(name, trace.WithAttributes(kvs...))
+//	}
+//
+// so that this package doesn't depend on any tracing library.
+type SpanEventRecorder interface {
+	AddEvent(name string, attributes map[string]string)
+}
+
+// RecordSpanEvents records each message as a span event named SpanEventName, in the order of Sort, so that analysis
+// findings can be correlated with the traces of the work that ran the analysis.
+func (ms *Messages) RecordSpanEvents(r SpanEventRecorder) {
+	ms.Each(func(_ int, m Message) {
+		attrs := map[string]string{
+			SpanAttributeCode:  m.Type.Code(),
+			SpanAttributeLevel: m.Type.Level().String(),
+			SpanAttributeText:  m.Text(),
+		}
+		if key := m.ResourceKey(); key != "" {
+			attrs[SpanAttributeResource] = key
+		}
+		r.AddEvent(SpanEventName, attrs)
+	})
+}
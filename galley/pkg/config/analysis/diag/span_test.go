@@ -0,0 +1,59 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type spanEvent struct {
+	name       string
+	attributes map[string]string
+}
+
+type fakeSpan struct {
+	events []spanEvent
+}
+
+func (s *fakeSpan) AddEvent(name string, attributes map[string]string) {
+	s.events = append(s.events, spanEvent{name, attributes})
+}
+
+func TestMessages_RecordSpanEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := Messages{
+		NewMessage(NewMessageType(Warning, "C1", "Template: %q"), nil, "w"),
+		NewMessage(NewMessageType(Error, "B1", "Template: %q"), mockSchemaResource("prod", "reviews"), "e"),
+	}
+
+	span := &fakeSpan{}
+	msgs.RecordSpanEvents(span)
+	g.Expect(span.events).To(Equal([]spanEvent{
+		{SpanEventName, map[string]string{
+			SpanAttributeCode:     "B1",
+			SpanAttributeLevel:    "Error",
+			SpanAttributeResource: "prod/VirtualService/reviews",
+			SpanAttributeText:     `Template: "e"`,
+		}},
+		{SpanEventName, map[string]string{
+			SpanAttributeCode:  "C1",
+			SpanAttributeLevel: "Warning",
+			SpanAttributeText:  `Template: "w"`,
+		}},
+	}))
+}
@@ -0,0 +1,137 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateFuncs are the functions available to message templates using text/template syntax.
+var templateFuncs = template.FuncMap{
+	// quote formats a value as with the %q verb
+	"quote": func(v interface{}) string {
+		return fmt.Sprintf("%q", v)
+	},
+}
+
+// usesTemplateSyntax returns true if a message template refers to its arguments by name using text/template syntax,
+// rather than being a printf format string.
+func usesTemplateSyntax(tmpl string) bool {
+	return strings.Contains(tmpl, "{{")
+}
+
+func parseTemplate(name, tmpl string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+}
+
+// ValidateTemplate returns an error if a message template using text/template syntax fails to parse as it would for
+// rendering, for example due to a syntax error or a reference to an undefined function. Printf templates are not
+// checked.
+func ValidateTemplate(tmpl string) error {
+	if !usesTemplateSyntax(tmpl) {
+		return nil
+	}
+	_, err := parseTemplate("message", tmpl)
+	return err
+}
+
+// TemplateFuncNames returns the sorted names of the functions available to message templates, in addition to the
+// builtin functions of text/template.
+func TemplateFuncNames() []string {
+	names := make([]string, 0, len(templateFuncs))
+	for name := range templateFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TemplateFields returns the names of the arguments a template using text/template syntax refers to, in the order they
+// are first referred to, or nil for printf templates. Fields of arguments are not included, e.g. {{.port.Name}} refers
+// to the argument port. Neither are references inside range and with actions, other than through $, as the dot refers
+// to something else there.
+func TemplateFields(tmpl string) ([]string, error) {
+	if !usesTemplateSyntax(tmpl) {
+		return nil, nil
+	}
+	t, err := parseTemplate("message", tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+	var walk func(n parse.Node, dotIsArgs bool)
+	walk = func(n parse.Node, dotIsArgs bool) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c, dotIsArgs)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, dotIsArgs)
+		case *parse.IfNode:
+			walk(n.Pipe, dotIsArgs)
+			walk(n.List, dotIsArgs)
+			walk(n.ElseList, dotIsArgs)
+		case *parse.RangeNode:
+			walk(n.Pipe, dotIsArgs)
+			walk(n.List, false)
+			walk(n.ElseList, dotIsArgs)
+		case *parse.WithNode:
+			walk(n.Pipe, dotIsArgs)
+			walk(n.List, false)
+			walk(n.ElseList, dotIsArgs)
+		case *parse.TemplateNode:
+			walk(n.Pipe, dotIsArgs)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c, dotIsArgs)
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a, dotIsArgs)
+			}
+		case *parse.ChainNode:
+			walk(n.Node, dotIsArgs)
+		case *parse.FieldNode:
+			if dotIsArgs {
+				add(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				add(n.Ident[1])
+			}
+		}
+	}
+	walk(t.Tree.Root, true)
+	return fields, nil
+}
@@ -0,0 +1,85 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage_TextNamedArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	// The template refers to the args in a different order than they are declared
+	mt := NewMessageType(Error, "IST0042", "Cheese {{quote .cheese}} is not available on {{.pizza}} ({{.reason}})",
+		WithArgs("pizza", "cheese", "reason"))
+	m := NewMessage(mt, nil, "margherita", "Feta", errors.New("out of stock"))
+
+	g.Expect(m.Text()).To(Equal(`Cheese "Feta" is not available on margherita (out of stock)`))
+	g.Expect(m.String()).To(Equal(`Error [IST0042] Cheese "Feta" is not available on margherita (out of stock)`))
+}
+
+func TestMessage_TextPrintf(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q", WithArgs("cheese"))
+	m := NewMessage(mt, nil, "Feta")
+
+	g.Expect(m.Text()).To(Equal(`Cheese type not found: "Feta"`))
+}
+
+func TestMessage_TextTemplateErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese {{.cheese", WithArgs("cheese"))
+	m := NewMessage(mt, nil, "Feta")
+	g.Expect(m.Text()).To(HavePrefix("Cheese {{.cheese (error parsing template: "))
+
+	mt = NewMessageType(Error, "IST0042", "Cheese {{.cracker}}", WithArgs("cheese"))
+	m = NewMessage(mt, nil, "Feta")
+	g.Expect(m.Text()).To(HavePrefix("Cheese {{.cracker}} (error rendering template: "))
+}
+
+func TestValidateTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateTemplate("Cheese type not found: %q")).To(Succeed())
+	g.Expect(ValidateTemplate("Cheese {{quote .cheese}} on {{.pizza}}")).To(Succeed())
+	g.Expect(ValidateTemplate("Cheese {{.cheese")).To(HaveOccurred())
+	g.Expect(ValidateTemplate("Cheese {{.cheese | pluralize}}")).To(MatchError(ContainSubstring(`function "pluralize" not defined`)))
+	g.Expect(TemplateFuncNames()).To(Equal([]string{"quote"}))
+}
+
+func TestTemplateFields(t *testing.T) {
+	g := NewWithT(t)
+
+	fields, err := TemplateFields("Cheese {{quote .cheese}} on {{.pizza.Name}} for {{.cheese}}")
+	g.Expect(err).To(BeNil())
+	g.Expect(fields).To(Equal([]string{"cheese", "pizza"}))
+
+	// The dot is an element of the range, so only references through $ are to args
+	fields, err = TemplateFields("{{if .ok}}{{range .toppings}}{{.Name}} on {{$.pizza}}{{else}}{{.empty}}{{end}}{{end}}")
+	g.Expect(err).To(BeNil())
+	g.Expect(fields).To(Equal([]string{"ok", "toppings", "pizza", "empty"}))
+
+	fields, err = TemplateFields("Cheese type not found: %q")
+	g.Expect(err).To(BeNil())
+	g.Expect(fields).To(BeNil())
+
+	_, err = TemplateFields("Cheese {{.cheese")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,81 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// TemplateFormatter renders each message with a text/template supplied by the caller, one message per line, for
+// output layouts that no other formatter provides. The template refers to the fields of a message by name:
+//
+//	{{.level}} {{.code}} {{.namespace}}/{{.kind}}/{{.name}}: {{.message}} ({{.url}})
+//
+// Fields that are unknown for a message, such as the resource of a message without one, are empty. Messages are
+// rendered in the order given.
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+var _ Formatter = &TemplateFormatter{}
+
+// templateFormatterFields are the names of the fields available to the templates of a TemplateFormatter.
+var templateFormatterFields = []string{"code", "level", "namespace", "kind", "name", "message", "url"}
+
+// NewTemplateFormatter returns a TemplateFormatter rendering messages with the given template. It returns an error if
+// the template doesn't parse, or refers to a field that doesn't exist, so that mistakes surface before rendering.
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %v", err)
+	}
+
+	// Render empty fields, which catches references to fields that don't exist
+	empty := make(map[string]string, len(templateFormatterFields))
+	for _, f := range templateFormatterFields {
+		empty[f] = ""
+	}
+	if err := tmpl.Execute(io.Discard, empty); err != nil {
+		return nil, fmt.Errorf("invalid output template, the fields available are %v: %v", templateFormatterFields, err)
+	}
+
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+// Format implements Formatter
+func (f *TemplateFormatter) Format(ms Messages) (string, error) {
+	lines := make([]string, 0, len(ms))
+	for i := range ms {
+		m := &ms[i]
+		namespace, kind, name := m.resourceCoordinates()
+		var b strings.Builder
+		if err := f.tmpl.Execute(&b, map[string]string{
+			"code":      m.Type.Code(),
+			"level":     m.Type.Level().String(),
+			"namespace": namespace,
+			"kind":      kind,
+			"name":      name,
+			"message":   m.Text(),
+			"url":       m.documentationURL(),
+		}); err != nil {
+			return "", fmt.Errorf("failed to render message %s: %v", m.Type.Code(), err)
+		}
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,57 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+
+	msgs := Messages{
+		NewMessage(mt, mockSchemaResource("prod", "reviews"), "Feta"),
+		NewMessage(mt, nil, "Gouda"),
+	}
+
+	f, err := NewTemplateFormatter(`{{.level}} {{.code}} {{.namespace}}/{{.kind}}/{{.name}}: {{quote .message}}`)
+	g.Expect(err).To(BeNil())
+	output, err := f.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"Error IST0042 prod/VirtualService/reviews: \"Cheese type not found: \\\"Feta\\\"\"\n" +
+			"Error IST0042 //: \"Cheese type not found: \\\"Gouda\\\"\"",
+	))
+
+	f, err = NewTemplateFormatter(`{{.url}}`)
+	g.Expect(err).To(BeNil())
+	output, err = f.Format(msgs[:1])
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(HaveSuffix("/ist0042/"))
+}
+
+func TestTemplateFormatter_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := NewTemplateFormatter(`{{.code`)
+	g.Expect(err).To(MatchError(ContainSubstring("invalid output template")))
+
+	_, err = NewTemplateFormatter(`{{.severity}}`)
+	g.Expect(err).To(MatchError(ContainSubstring("the fields available are [code level namespace kind name message url]")))
+}
@@ -0,0 +1,114 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	treeIndent         = "  "
+	treeClusterScoped  = "(cluster-scoped)"
+	treeUnknownKind    = "(unknown kind)"
+	treeNoResourceNode = "(no resource)"
+)
+
+// TreeFormatter renders messages as a tree grouped by the ownership of their resources: namespace, then kind, then
+// resource name, then the messages themselves. Each node is annotated with the number of messages beneath it, and
+// nodes are sorted by name. Messages without a resource are listed together under a single node at the end.
+type TreeFormatter struct{}
+
+var _ Formatter = TreeFormatter{}
+
+type treeNode struct {
+	children map[string]*treeNode
+	messages Messages
+	count    int
+}
+
+func (n *treeNode) child(name string) *treeNode {
+	if n.children == nil {
+		n.children = make(map[string]*treeNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &treeNode{}
+		n.children[name] = c
+	}
+	return c
+}
+
+// Format implements Formatter
+func (f TreeFormatter) Format(ms Messages) (string, error) {
+	sorted := append(ms[:0:0], ms...)
+	sorted.Sort()
+
+	root := &treeNode{}
+	orphans := &treeNode{}
+	for _, m := range sorted {
+		if m.Resource == nil {
+			orphans.messages = append(orphans.messages, m)
+			orphans.count++
+			continue
+		}
+
+		namespace, kind, name := m.resourceCoordinates()
+		if namespace == "" {
+			namespace = treeClusterScoped
+		}
+		if kind == "" {
+			kind = treeUnknownKind
+		}
+
+		root.count++
+		nsNode := root.child(namespace)
+		nsNode.count++
+		kindNode := nsNode.child(kind)
+		kindNode.count++
+		nameNode := kindNode.child(name)
+		nameNode.count++
+		nameNode.messages = append(nameNode.messages, m)
+	}
+
+	var lines []string
+	writeTree(&lines, root, 0)
+	if orphans.count > 0 {
+		lines = append(lines, fmt.Sprintf("%s (%d)", treeNoResourceNode, orphans.count))
+		writeTree(&lines, orphans, 1)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func writeTree(lines *[]string, n *treeNode, depth int) {
+	indent := strings.Repeat(treeIndent, depth)
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := n.children[name]
+		*lines = append(*lines, fmt.Sprintf("%s%s (%d)", indent, name, child.count))
+		writeTree(lines, child, depth+1)
+	}
+
+	for _, m := range n.messages {
+		*lines = append(*lines, fmt.Sprintf("%s%v [%v] %s", indent, m.Type.Level(), m.Type.Code(), m.Text()))
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+func mockSchemaResource(namespace, name string) *resource.Instance {
+	r := MockResource(name)
+	r.Metadata.FullName = resource.NewFullName(resource.Namespace(namespace), resource.LocalName(name))
+	r.Metadata.Schema = collections.IstioNetworkingV1Alpha3Virtualservices.Resource()
+	return r
+}
+
+func TestTreeFormatter(t *testing.T) {
+	g := NewWithT(t)
+
+	mt := NewMessageType(Error, "B1", "Template: %q")
+	wt := NewMessageType(Warning, "C1", "Template: %q")
+
+	msgs := Messages{
+		NewMessage(wt, mockSchemaResource("prod", "reviews"), "w"),
+		NewMessage(mt, mockSchemaResource("prod", "reviews"), "e"),
+		NewMessage(mt, mockSchemaResource("dev", "ratings"), "e"),
+		NewMessage(mt, MockResource("Unknown"), "u"),
+		NewMessage(mt, nil, "none"),
+	}
+
+	output, err := TreeFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`default (1)
+  (unknown kind) (1)
+    Unknown (1)
+      Error [B1] Template: "u"
+dev (1)
+  VirtualService (1)
+    ratings (1)
+      Error [B1] Template: "e"
+prod (2)
+  VirtualService (2)
+    reviews (2)
+      Error [B1] Template: "e"
+      Warning [C1] Template: "w"
+(no resource) (1)
+  Error [B1] Template: "none"`))
+}
+
+func TestTreeFormatter_Empty(t *testing.T) {
+	g := NewWithT(t)
+
+	output, err := TreeFormatter{}.Format(Messages{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(BeEmpty())
+}
@@ -0,0 +1,40 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"unicode/utf8"
+)
+
+// Ellipsis marks text that has been truncated by Truncate.
+const Ellipsis = "..."
+
+// Truncate shortens s to at most maxBytes bytes, replacing the end of it with Ellipsis. It never splits a multi-byte
+// UTF-8 sequence. If maxBytes is not positive, or s is already short enough, s is returned unchanged.
+func Truncate(s string, maxBytes int) string {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s
+	}
+
+	suffix := Ellipsis
+	if maxBytes < len(suffix) {
+		suffix = ""
+	}
+	end := maxBytes - len(suffix)
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + suffix
+}
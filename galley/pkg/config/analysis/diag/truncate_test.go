@@ -0,0 +1,49 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		s        string
+		maxBytes int
+		expected string
+	}{
+		{"Cheese type not found", 0, "Cheese type not found"},
+		{"Cheese type not found", 21, "Cheese type not found"},
+		{"Cheese type not found", 10, "Cheese ..."},
+		{"Cheese", 2, "Ch"},
+		{"Käse", 4, "K..."},
+		// "ä" is two bytes, and must not be split
+		{"Käse nicht gefunden", 5, "K..."},
+		{"Käse nicht gefunden", 6, "Kä..."},
+		{"日本語", 2, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.s, func(t *testing.T) {
+			g := NewWithT(t)
+			actual := Truncate(c.s, c.maxBytes)
+			g.Expect(actual).To(Equal(c.expected))
+			g.Expect(utf8.ValidString(actual)).To(BeTrue())
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultWebhookTimeout is the timeout of each attempt to post to a webhook, if the sink doesn't set one
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// DefaultWebhookRetryDelay is the delay before retrying a failed post to a webhook, if the sink doesn't set one
+	DefaultWebhookRetryDelay = time.Second
+)
+
+// WebhookSink posts messages rendered by a Formatter to a webhook, e.g. to share analysis results in a chat channel.
+// Posts that fail with a network error or a 429 or 5xx status are retried; other statuses fail immediately.
+type WebhookSink struct {
+	// URL is the webhook endpoint the messages are posted to
+	URL string
+
+	// Formatter renders the messages into the payload
+	Formatter Formatter
+
+	// JSONField, if set, posts the rendered messages as the given string field of a JSON object rather than as plain
+	// text. Slack and Microsoft Teams incoming webhooks both accept the field "text".
+	JSONField string
+
+	// MaxPerCode, if positive, posts at most this many messages with each code, as with Messages.CapPerCode
+	MaxPerCode int
+
+	// MaxBytes, if positive, limits the body posted to at most this many bytes, to respect the payload size limit of
+	// the webhook. Messages are dropped, last in the order of Sort first, until the body fits, rather than cutting the
+	// rendered output, which would leave structured formats such as SARIF invalid.
+	MaxBytes int
+
+	// Retries is the number of times a failed post is retried
+	Retries int
+
+	// RetryDelay is the delay before each retry, DefaultWebhookRetryDelay if not positive
+	RetryDelay time.Duration
+
+	// Timeout is the timeout of each attempt, DefaultWebhookTimeout if not positive
+	Timeout time.Duration
+
+	// Client is the HTTP client used to post, http.DefaultClient if nil
+	Client *http.Client
+}
+
+// Send renders the messages and posts them to the webhook, returning an error if they couldn't be rendered or the
+// last attempt to post them failed.
+func (s *WebhookSink) Send(ctx context.Context, ms Messages) error {
+	capped, _ := ms.CapPerCode(s.MaxPerCode)
+	body, contentType, err := s.payload(capped)
+	if err != nil {
+		return err
+	}
+	if s.MaxBytes > 0 && len(body) > s.MaxBytes {
+		// Find the number of messages that fit: the payload of each further message exceeds the limit
+		var searchErr error
+		n := sort.Search(len(capped), func(i int) bool {
+			b, _, err := s.payload(capped[:i+1])
+			if err != nil {
+				searchErr = err
+				return true
+			}
+			return len(b) > s.MaxBytes
+		})
+		if searchErr != nil {
+			return searchErr
+		}
+		if n == 0 {
+			return fmt.Errorf("failed to fit any messages in the webhook payload limit of %d bytes", s.MaxBytes)
+		}
+		if body, contentType, err = s.payload(capped[:n]); err != nil {
+			return err
+		}
+	}
+
+	delay := s.RetryDelay
+	if delay <= 0 {
+		delay = DefaultWebhookRetryDelay
+	}
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(ctx, body, contentType)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.Retries {
+			return fmt.Errorf("failed to post messages to webhook after %d attempt(s): %v", attempt+1, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to post messages to webhook: %v (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// payload renders the messages into the body to post, returning it along with its content type
+func (s *WebhookSink) payload(ms Messages) ([]byte, string, error) {
+	text, err := s.Formatter.Format(ms)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render messages for webhook: %v", err)
+	}
+	if s.JSONField == "" {
+		return []byte(text), "text/plain; charset=utf-8", nil
+	}
+	body, err := json.Marshal(map[string]string{s.JSONField: text})
+	return body, "application/json", err
+}
+
+// post makes a single attempt to post the body to the webhook, returning whether a failure is worth retrying
+func (s *WebhookSink) post(ctx context.Context, body []byte, contentType string) (bool, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// Drain the body, so that the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook responded with status %s", resp.Status)
+}
@@ -0,0 +1,145 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// webhookRecorder is a webhook responding with the given statuses in turn, recording the requests it receives
+type webhookRecorder struct {
+	statuses     []int
+	bodies       []string
+	contentTypes []string
+}
+
+func (w *webhookRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	w.bodies = append(w.bodies, string(b))
+	w.contentTypes = append(w.contentTypes, r.Header.Get("Content-Type"))
+	status := http.StatusOK
+	if len(w.statuses) > 0 {
+		status, w.statuses = w.statuses[0], w.statuses[1:]
+	}
+	rw.WriteHeader(status)
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	msgs := Messages{NewMessage(mt, nil, "Feta"), NewMessage(mt, nil, "Gouda")}
+
+	sink := &WebhookSink{URL: server.URL, Formatter: CompactFormatter{}}
+	g.Expect(sink.Send(context.Background(), msgs)).To(Succeed())
+	g.Expect(rec.bodies).To(Equal([]string{
+		"ERROR\tIST0042\t-/-/-\tCheese type not found: \"Feta\"\n" +
+			"ERROR\tIST0042\t-/-/-\tCheese type not found: \"Gouda\"",
+	}))
+	g.Expect(rec.contentTypes).To(Equal([]string{"text/plain; charset=utf-8"}))
+}
+
+func TestWebhookSink_SendJSONCapped(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	msgs := Messages{NewMessage(mt, nil, "Feta"), NewMessage(mt, nil, "Gouda"), NewMessage(mt, nil, "Brie")}
+
+	// Capping keeps two messages, but only the first fits once escaped as JSON
+	sink := &WebhookSink{URL: server.URL, Formatter: CompactFormatter{}, JSONField: "text", MaxPerCode: 2, MaxBytes: 100}
+	g.Expect(sink.Send(context.Background(), msgs)).To(Succeed())
+	g.Expect(rec.bodies).To(Equal([]string{`{"text":"ERROR\tIST0042\t-/-/-\tCheese type not found: \"Brie\""}`}))
+	g.Expect(rec.contentTypes).To(Equal([]string{"application/json"}))
+
+	// Nothing is posted if not even one message fits
+	sink.MaxBytes = 30
+	g.Expect(sink.Send(context.Background(), msgs)).To(MatchError(ContainSubstring("limit of 30 bytes")))
+	g.Expect(rec.bodies).To(HaveLen(1))
+}
+
+func TestWebhookSink_SendStructuredCapped(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	mt := NewMessageType(Error, "IST0042", "Cheese type not found: %q")
+	var msgs Messages
+	for _, cheese := range []string{"Brie", "Edam", "Feta", "Gouda", "Havarti"} {
+		msgs.Add(NewMessage(mt, nil, cheese))
+	}
+	full, err := SARIFFormatter{}.Format(msgs)
+	g.Expect(err).To(BeNil())
+
+	// Structured output is never cut, so the body stays valid JSON within the limit
+	sink := &WebhookSink{URL: server.URL, Formatter: SARIFFormatter{}, MaxBytes: len(full) - 1}
+	g.Expect(sink.Send(context.Background(), msgs)).To(Succeed())
+	g.Expect(len(rec.bodies[0])).To(BeNumerically("<=", len(full)-1))
+	var log sarifLog
+	g.Expect(json.Unmarshal([]byte(rec.bodies[0]), &log)).To(Succeed())
+	g.Expect(log.Runs[0].Results).To(HaveLen(4))
+}
+
+func TestWebhookSink_SendRetries(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &webhookRecorder{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL, Formatter: CompactFormatter{}, Retries: 2, RetryDelay: time.Millisecond}
+	g.Expect(sink.Send(context.Background(), nil)).To(Succeed())
+	g.Expect(rec.bodies).To(HaveLen(3))
+
+	// Running out of retries fails
+	rec = &webhookRecorder{statuses: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	failing := httptest.NewServer(rec)
+	defer failing.Close()
+	sink.URL = failing.URL
+	sink.Retries = 1
+	err := sink.Send(context.Background(), nil)
+	g.Expect(err).To(MatchError(ContainSubstring("after 2 attempt(s): webhook responded with status 502 Bad Gateway")))
+	g.Expect(rec.bodies).To(HaveLen(2))
+}
+
+func TestWebhookSink_SendClientErrorNotRetried(t *testing.T) {
+	g := NewWithT(t)
+
+	rec := &webhookRecorder{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL, Formatter: CompactFormatter{}, Retries: 3, RetryDelay: time.Millisecond}
+	err := sink.Send(context.Background(), nil)
+	g.Expect(err).To(MatchError(ContainSubstring("after 1 attempt(s): webhook responded with status 400 Bad Request")))
+	g.Expect(rec.bodies).To(HaveLen(1))
+}
@@ -104,7 +104,9 @@ func TestAnalyzersRun(t *testing.T) {
 
 	result, err := sa.Analyze(cancel)
 	g.Expect(err).To(BeNil())
-	g.Expect(result.Messages).To(ConsistOf(m))
+	attributed := m
+	attributed.Analyzer = a.Metadata().Name
+	g.Expect(result.Messages).To(ConsistOf(attributed))
 	g.Expect(collectionAccessed).To(Equal(basicmeta.K8SCollection1.Name()))
 	g.Expect(result.ExecutedAnalyzers).To(ConsistOf(a.Metadata().Name))
 }
@@ -131,7 +133,9 @@ func TestFilterOutputByNamespace(t *testing.T) {
 
 	result, err := sa.Analyze(cancel)
 	g.Expect(err).To(BeNil())
-	g.Expect(result.Messages).To(ConsistOf(msg1))
+	attributed := msg1
+	attributed.Analyzer = a.Metadata().Name
+	g.Expect(result.Messages).To(ConsistOf(attributed))
 }
 
 func TestAddInMemorySource(t *testing.T) {
@@ -0,0 +1,64 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"sort"
+)
+
+// Rule describes an analyzer and the messages it may report, as declared in its metadata.
+type Rule struct {
+	Analyzer    string        `json:"analyzer"`
+	Description string        `json:"description,omitempty"`
+	Messages    []RuleMessage `json:"messages"`
+}
+
+// RuleMessage describes a type of message an analyzer may report.
+type RuleMessage struct {
+	Code        string `json:"code"`
+	Name        string `json:"name,omitempty"`
+	Level       string `json:"level"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// RulesManifest returns a rule for each of the given analyzers, sorted by analyzer name, so that tooling can tell which
+// analyzer reports each message. Combined analyzers are replaced by the analyzers they combine. The messages of each
+// rule are sorted by code.
+func RulesManifest(analyzers ...Analyzer) []Rule {
+	rules := make([]Rule, 0, len(analyzers))
+	for _, a := range analyzers {
+		if c, ok := a.(*CombinedAnalyzer); ok {
+			rules = append(rules, RulesManifest(c.analyzers...)...)
+			continue
+		}
+
+		md := a.Metadata()
+		r := Rule{Analyzer: md.Name, Description: md.Description, Messages: make([]RuleMessage, 0, len(md.Messages))}
+		for _, mt := range md.Messages {
+			r.Messages = append(r.Messages, RuleMessage{
+				Code:        mt.Code(),
+				Name:        mt.Name(),
+				Level:       mt.Level().String(),
+				Description: mt.Description(),
+				URL:         mt.URL(),
+			})
+		}
+		sort.Slice(r.Messages, func(i, j int) bool { return r.Messages[i].Code < r.Messages[j].Code })
+		rules = append(rules, r)
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Analyzer < rules[j].Analyzer })
+	return rules
+}
@@ -0,0 +1,51 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+)
+
+func TestRulesManifest(t *testing.T) {
+	g := NewWithT(t)
+
+	mt1 := diag.NewMessageType(diag.Error, "IST0002", "Template", diag.WithName("Second"),
+		diag.WithDescription("The second message"), diag.WithURL("https://example.com/ist0002"))
+	mt2 := diag.NewMessageType(diag.Warning, "IST0001", "Template", diag.WithName("First"))
+
+	b := &analyzer{name: "b", messages: []*diag.MessageType{mt1, mt2}}
+	a := &analyzer{name: "a", messages: []*diag.MessageType{mt1}}
+	c := &analyzer{name: "c"}
+
+	combined := Combine("combined", b, Combine("inner", a), c)
+	g.Expect(combined.Metadata().Messages).To(Equal([]*diag.MessageType{mt1, mt2}))
+
+	second := RuleMessage{
+		Code:        "IST0002",
+		Name:        "Second",
+		Level:       "Error",
+		Description: "The second message",
+		URL:         "https://example.com/ist0002",
+	}
+	g.Expect(RulesManifest(combined)).To(Equal([]Rule{
+		{Analyzer: "a", Messages: []RuleMessage{second}},
+		{Analyzer: "b", Messages: []RuleMessage{{Code: "IST0001", Name: "First", Level: "Warning"}, second}},
+		{Analyzer: "c", Messages: []RuleMessage{}},
+	}))
+}
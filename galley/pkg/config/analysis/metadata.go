@@ -15,6 +15,7 @@
 package analysis
 
 import (
+	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/pkg/config/schema/collection"
 )
 
@@ -25,4 +26,7 @@ type Metadata struct {
 	// field is displayed to users when --list-analyzers is called.
 	Description string
 	Inputs      collection.Names
+	// Messages are the types of the messages the analyzer may report. They are listed in the rules manifest, so that
+	// tooling can tell which analyzer reports each message.
+	Messages []*diag.MessageType
 }
@@ -0,0 +1,36 @@
+// GENERATED FILE -- DO NOT EDIT
+//
+
+package msg
+
+import (
+	"fmt"
+)
+
+func ExampleNewInternalError() {
+	m := NewInternalError(nil, "sample-string")
+	fmt.Println(m.String())
+	// Output:
+	// Error [IST0001] Internal error: sample-string
+}
+
+func ExampleNewDeprecated() {
+	m := NewDeprecated(nil, "sample-string")
+	fmt.Println(m.String())
+	// Output:
+	// Warning [IST0002] Deprecated: sample-string
+}
+
+func ExampleNewReferencedResourceNotFound() {
+	m := NewReferencedResourceNotFound(nil, "sample-string", "sample-string")
+	fmt.Println(m.String())
+	// Output:
+	// Error [IST0101] Referenced sample-string not found: "sample-string"
+}
+
+func ExampleNewNamespaceNotInjected() {
+	m := NewNamespaceNotInjected(nil, "sample-string", "sample-string")
+	fmt.Println(m.String())
+	// Output:
+	// Info [IST0102] The namespace is not enabled for Istio injection. Run 'kubectl label namespace sample-string istio-injection=enabled' to enable it, or 'kubectl label namespace sample-string istio-injection=disabled' to explicitly mark it as not needing injection.
+}
@@ -0,0 +1,65 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	_ "embed" // for embedding the message metadata
+	"encoding/json"
+	"sync"
+)
+
+// Example is an example of the configuration a message is about, as declared in messages.yaml.
+type Example struct {
+	// Description explains what the example shows
+	Description string `json:"description"`
+
+	// Bad is configuration that the message is reported for, if any
+	Bad string `json:"bad,omitempty"`
+
+	// Good is configuration that fixes it, if any
+	Good string `json:"good,omitempty"`
+}
+
+//go:embed messages.json
+var metadataJSON []byte
+
+var examples struct {
+	once   sync.Once
+	byCode map[string][]Example
+}
+
+// ExamplesFor returns the examples declared in messages.yaml for the message type with the given code, or nil if it
+// has none.
+func ExamplesFor(code string) []Example {
+	examples.once.Do(func() {
+		var metadata struct {
+			Messages []struct {
+				Code     string    `json:"code"`
+				Examples []Example `json:"examples"`
+			} `json:"messages"`
+		}
+		// The metadata is generated along with this package, so it can only fail to parse if it is out of date
+		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+			panic(err)
+		}
+		examples.byCode = make(map[string][]Example)
+		for _, m := range metadata.Messages {
+			if len(m.Examples) > 0 {
+				examples.byCode[m.Code] = m.Examples
+			}
+		}
+	})
+	return examples.byCode[code]
+}
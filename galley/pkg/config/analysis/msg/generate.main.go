@@ -17,100 +17,1175 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
 	"os"
+	pathpkg "path"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-version"
+
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+)
+
+const (
+	// codeRegex is the default pattern codes must follow, unless messages.yaml sets codePattern
+	codeRegex = `^IST\d\d\d\d$`
+	nameRegex = `^[[:upper:]]\w*$`
+
+	// actionRegex matches the actions of text/template templates, e.g. "{{.detail}}"
+	actionRegex = `\{\{.*?\}\}`
+
+	// verbRegex matches the verbs of printf format strings, including the "%%" escape
+	verbRegex = `%[-+# 0]*\d*(?:\.\d+)?[[:alpha:]%]`
+)
+
+var (
+	requireURL         = flag.Bool("require-url", false, "Fail validation if any message does not have a url")
+	strict             = flag.Bool("strict", false, "Fail validation if there are any warnings, after reporting all of them")
+	jsonOutput         = flag.String("json-output", "", "If set, also write the message metadata as JSON to this file, e.g. for use with go:embed")
+	potOutput          = flag.String("translations-output", "", "If set, also write a gettext template of the message templates to this file, for translators")
+	mdOutput           = flag.String("markdown-output", "", "If set, also write a Markdown reference of the messages to this file")
+	mdCategory         = flag.Bool("markdown-by-category", false, "Group the Markdown reference by category, with an index of the categories")
+	metrics            = flag.Bool("metric-descriptors", false, "Also generate MetricDescriptors, describing a metric for each message")
+	shimOutput         = flag.String("shim-output", "", "If set, also write a deprecated compatibility shim to this file, re-exporting the messages from -shim-import")
+	shimImport         = flag.String("shim-import", "", "The import path the messages have moved to, which the compatibility shim refers to")
+	examplesOutput     = flag.String("examples-output", "", "If set, also write Go examples of a few message constructors to this _test.go file, for godoc")
+	codeMapOutput      = flag.String("code-map-output", "", "If set, also write the name of the message with each code to this file, to commit so that diffs reveal reassigned codes")
+	deprecationsOutput = flag.String("deprecations-output", "", "If set, also write a Markdown report of the deprecated messages and code aliases to this file, e.g. for release notes")
+	allowedVerbs       = flag.String("allowed-verbs", "%s,%d", "Comma separated verbs that printf templates may use, besides the \"%%\" escape")
 )
 
-const (
-	codeRegex = `^IST\d\d\d\d$`
-	nameRegex = `^[[:upper:]]\w*$`
-)
+// Utility for generating messages.gen.go. Called from gen.go
+func main() {
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) > 0 && args[0] == "next-code" {
+		nextCodeMain(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == "sort" {
+		sortMain(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == "add" {
+		addMain(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == "check-docs" {
+		checkDocsMain(args[1:])
+		return
+	}
+
+	if len(args) != 2 {
+		fmt.Println("Invalid args:", os.Args)
+		os.Exit(-1)
+	}
+
+	input := args[0]
+	output := args[1]
+
+	if (*shimOutput == "") != (*shimImport == "") {
+		fmt.Println("Invalid args: -shim-output and -shim-import must be set together")
+		os.Exit(-1)
+	}
+
+	m, err := read(input)
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		os.Exit(-2)
+	}
+
+	err = validate(m)
+	if err != nil {
+		fmt.Println("Error validating messages:", err)
+		os.Exit(-3)
+	}
+
+	warnings := lint(m)
+	for _, w := range warnings {
+		fmt.Println("Warning:", w)
+	}
+	if *strict && len(warnings) > 0 {
+		fmt.Printf("Error validating messages: %d warning(s), which are errors with -strict\n", len(warnings))
+		os.Exit(-3)
+	}
+
+	if *metrics {
+		if err = validateMetricNames(m); err != nil {
+			fmt.Println("Error validating messages:", err)
+			os.Exit(-3)
+		}
+	}
+
+	code, err := generate(m)
+	if err != nil {
+		fmt.Println("Error generating code:", err)
+		os.Exit(-4)
+	}
+
+	if err = os.WriteFile(output, []byte(code), os.ModePerm); err != nil {
+		fmt.Println("Error writing output file:", err)
+		os.Exit(-5)
+	}
+
+	if *jsonOutput != "" {
+		j, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			fmt.Println("Error generating JSON:", err)
+			os.Exit(-4)
+		}
+		if err = os.WriteFile(*jsonOutput, append(j, '\n'), os.ModePerm); err != nil {
+			fmt.Println("Error writing JSON output file:", err)
+			os.Exit(-5)
+		}
+	}
+
+	if *potOutput != "" {
+		if err = os.WriteFile(*potOutput, []byte(translations(m)), os.ModePerm); err != nil {
+			fmt.Println("Error writing translations output file:", err)
+			os.Exit(-5)
+		}
+	}
+
+	if *codeMapOutput != "" {
+		if err = os.WriteFile(*codeMapOutput, []byte(codeMap(m)), os.ModePerm); err != nil {
+			fmt.Println("Error writing code map output file:", err)
+			os.Exit(-5)
+		}
+	}
+
+	if *mdOutput != "" {
+		if err = os.WriteFile(*mdOutput, []byte(markdown(m, *mdCategory)), os.ModePerm); err != nil {
+			fmt.Println("Error writing Markdown output file:", err)
+			os.Exit(-5)
+		}
+	}
+
+	if *deprecationsOutput != "" {
+		if err = os.WriteFile(*deprecationsOutput, []byte(deprecations(m)), os.ModePerm); err != nil {
+			fmt.Println("Error writing deprecations output file:", err)
+			os.Exit(-5)
+		}
+	}
+
+	if *examplesOutput != "" {
+		examples, err := generateExamples(m)
+		if err != nil {
+			fmt.Println("Error generating examples:", err)
+			os.Exit(-4)
+		}
+		if err = os.WriteFile(*examplesOutput, []byte(examples), os.ModePerm); err != nil {
+			fmt.Println("Error writing examples output file:", err)
+			os.Exit(-5)
+		}
+	}
+
+	if *shimOutput != "" {
+		shim, err := generateShim(m, *shimImport)
+		if err != nil {
+			fmt.Println("Error generating compatibility shim:", err)
+			os.Exit(-4)
+		}
+		if err = os.WriteFile(*shimOutput, []byte(shim), os.ModePerm); err != nil {
+			fmt.Println("Error writing compatibility shim file:", err)
+			os.Exit(-5)
+		}
+	}
+}
+
+// nextCodeMain prints the lowest unused code in a category. Usage: next-code <category> <input>
+func nextCodeMain(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Invalid args for next-code, expected <category> <input>:", args)
+		os.Exit(-1)
+	}
+
+	m, err := read(args[1])
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		os.Exit(-2)
+	}
+
+	if err = validate(m); err != nil {
+		fmt.Println("Error validating messages:", err)
+		os.Exit(-3)
+	}
+
+	code, err := nextCode(m, args[0])
+	if err != nil {
+		fmt.Println("Error finding next code:", err)
+		os.Exit(-4)
+	}
+	fmt.Println(code)
+}
+
+// addMain appends a new message to the input, with the lowest unused code in its category, and sorts the messages.
+// Fields not given as flags are prompted for. Usage: add [flags] <input>
+func addMain(args []string) {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	name := fs.String("name", "", "The name of the message, e.g. UnknownAnnotation")
+	categoryName := fs.String("category", "", "The category to allocate the code of the message in")
+	level := fs.String("level", "", fmt.Sprintf("The level of the message, one of %v", diag.GetAllLevelStrings()))
+	description := fs.String("description", "", "A description of the message")
+	templateText := fs.String("template", "", "The template of the message text")
+	msgArgs := fs.String("args", "", "Comma separated args of the message as name:type, e.g. \"annotation:string,port:int\"")
+	url := fs.String("url", "", "The documentation URL of the message, defaulting to the page for its code on istio.io")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Invalid args for add, expected [flags] <input>:", args)
+		os.Exit(-1)
+	}
+	input := fs.Arg(0)
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	stdin := bufio.NewReader(os.Stdin)
+	for _, f := range []string{"name", "category", "level", "description", "template", "args"} {
+		if set[f] {
+			continue
+		}
+		fmt.Printf("%s: ", fs.Lookup(f).Usage)
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Println("Error reading", f+":", err)
+			os.Exit(-1)
+		}
+		_ = fs.Set(f, strings.TrimSpace(line))
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		os.Exit(-2)
+	}
+
+	m, err := read(input)
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		os.Exit(-2)
+	}
+
+	code, err := nextCode(m, *categoryName)
+	if err != nil {
+		fmt.Println("Error finding next code:", err)
+		os.Exit(-4)
+	}
+
+	msg := message{
+		Name:        *name,
+		Code:        code,
+		Level:       *level,
+		Description: *description,
+		Template:    *templateText,
+		Url:         *url,
+	}
+	if msg.Url == "" {
+		msg.Url = fmt.Sprintf("https://istio.io/latest/docs/reference/config/analysis/%s/", strings.ToLower(code))
+	}
+	if *msgArgs != "" {
+		for _, a := range strings.Split(*msgArgs, ",") {
+			argName, typ := a, ""
+			if i := strings.Index(a, ":"); i >= 0 {
+				argName, typ = a[:i], a[i+1:]
+			}
+			msg.Args = append(msg.Args, arg{Name: strings.TrimSpace(argName), Type: strings.TrimSpace(typ)})
+		}
+	}
+
+	added, err := addMessage(content, msg)
+	if err != nil {
+		fmt.Println("Error adding message:", err)
+		os.Exit(-3)
+	}
+
+	if err = os.WriteFile(input, added, os.ModePerm); err != nil {
+		fmt.Println("Error writing metadata:", err)
+		os.Exit(-5)
+	}
+	fmt.Printf("Added %s with code %s\n", msg.Name, msg.Code)
+}
+
+// addMessage returns messages.yaml content with the given message appended, after checking that the result is valid.
+// The messages are then sorted by code, unless doing so would drop comments.
+func addMessage(content []byte, m message) ([]byte, error) {
+	var b bytes.Buffer
+	b.Write(bytes.TrimRight(content, "\n"))
+	fmt.Fprintf(&b, "\n\n  - name: %q\n", m.Name)
+	fmt.Fprintf(&b, "    code: %s\n", m.Code)
+	fmt.Fprintf(&b, "    level: %s\n", m.Level)
+	fmt.Fprintf(&b, "    description: %q\n", m.Description)
+	fmt.Fprintf(&b, "    template: %q\n", m.Template)
+	fmt.Fprintf(&b, "    url: %q\n", m.Url)
+	if len(m.Args) > 0 {
+		b.WriteString("    args:\n")
+		for _, a := range m.Args {
+			fmt.Fprintf(&b, "      - name: %s\n        type: %s\n", a.Name, a.Type)
+		}
+	}
+
+	ms := &messages{}
+	if err := yaml.Unmarshal(b.Bytes(), ms); err != nil {
+		return nil, err
+	}
+	if len(ms.Messages) == 0 || !reflect.DeepEqual(ms.Messages[len(ms.Messages)-1], m) {
+		return nil, fmt.Errorf("the message could not be appended, as messages is not the last section of the input")
+	}
+	if err := validate(ms); err != nil {
+		return nil, err
+	}
+	// Only the new message is of interest, as the others were valid before
+	for _, w := range lint(ms) {
+		if strings.Contains(w, fmt.Sprintf("%q", m.Name)) {
+			fmt.Println("Warning:", w)
+		}
+	}
+
+	sorted, preserved, err := sortMessages(b.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if !preserved {
+		fmt.Println("Warning: messages are not separated by blank lines, so the new message has been left at the end")
+		return b.Bytes(), nil
+	}
+	return sorted, nil
+}
+
+// checkDocsMain reports the differences between the codes of the messages and an inventory of the codes documented on
+// the docs site, failing if there are any. Usage: check-docs <inventory> <input>
+func checkDocsMain(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Invalid args for check-docs, expected <inventory> <input>:", args)
+		os.Exit(-1)
+	}
+
+	inventory, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Println("Error reading docs inventory:", err)
+		os.Exit(-2)
+	}
+
+	m, err := read(args[1])
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		os.Exit(-2)
+	}
+
+	if err = validate(m); err != nil {
+		fmt.Println("Error validating messages:", err)
+		os.Exit(-3)
+	}
+
+	undocumented, unknown := checkDocs(m, parseInventory(string(inventory)))
+	for _, code := range undocumented {
+		fmt.Printf("Code %s is not in the docs inventory\n", code)
+	}
+	for _, code := range unknown {
+		fmt.Printf("Code %s in the docs inventory has no message\n", code)
+	}
+	if len(undocumented) > 0 || len(unknown) > 0 {
+		fmt.Printf("Error checking docs: %d undocumented code(s), %d unknown code(s)\n", len(undocumented), len(unknown))
+		os.Exit(-3)
+	}
+}
+
+// parseInventory returns the codes in a docs inventory, which lists a code at the start of each line. Anything after
+// the code, blank lines and lines starting with "#" are ignored, so the code map output is an inventory too.
+func parseInventory(content string) []string {
+	var codes []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		codes = append(codes, fields[0])
+	}
+	return codes
+}
+
+// checkDocs returns the codes of messages missing from the inventory, and the codes in the inventory that aren't the
+// code or an alias of any message, both sorted. Aliases don't need to be documented, but may still be.
+func checkDocs(ms *messages, inventory []string) (undocumented []string, unknown []string) {
+	documented := make(map[string]bool)
+	for _, code := range inventory {
+		documented[code] = true
+	}
+
+	known := make(map[string]bool)
+	for _, m := range ms.Messages {
+		known[m.Code] = true
+		for _, a := range m.Aliases {
+			known[a] = true
+		}
+		if !documented[m.Code] {
+			undocumented = append(undocumented, m.Code)
+		}
+	}
+	for code := range documented {
+		if !known[code] {
+			unknown = append(unknown, code)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unknown)
+	return undocumented, unknown
+}
+
+// sortMain rewrites the input with its messages sorted by code. Usage: sort <input>
+func sortMain(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Invalid args for sort, expected <input>:", args)
+		os.Exit(-1)
+	}
+
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		os.Exit(-2)
+	}
+
+	sorted, preserved, err := sortMessages(b)
+	if err != nil {
+		fmt.Println("Error sorting messages:", err)
+		os.Exit(-4)
+	}
+	if !preserved {
+		fmt.Println("Warning: messages are not separated by blank lines, so comments have been dropped")
+	}
+
+	if err = os.WriteFile(args[0], sorted, os.ModePerm); err != nil {
+		fmt.Println("Error writing sorted metadata:", err)
+		os.Exit(-5)
+	}
+}
+
+// sortMessages returns messages.yaml content with its messages sorted by code, and whether its comments and layout
+// were preserved. Each message moves along with any comments above it, up to the previous blank line; comments
+// separated from the message below them by a blank line go with that message too. If a message can't be told apart
+// from its neighbors this way, the content is re-marshalled instead, which orders keys alphabetically and drops
+// comments. Either way, the sorted content is checked to have the same data as the original.
+func sortMessages(content []byte) ([]byte, bool, error) {
+	original := &messages{}
+	if err := yaml.Unmarshal(content, original); err != nil {
+		return nil, false, err
+	}
+	want := *original
+	want.Messages = append([]message(nil), original.Messages...)
+	sort.SliceStable(want.Messages, func(i, j int) bool { return want.Messages[i].Code < want.Messages[j].Code })
+
+	if sorted, ok := sortMessagesText(string(content)); ok {
+		got := &messages{}
+		if err := yaml.Unmarshal([]byte(sorted), got); err == nil && reflect.DeepEqual(*got, want) {
+			return []byte(sorted), true, nil
+		}
+	}
+
+	b, err := yaml.Marshal(want)
+	return b, false, err
+}
+
+// sortMessagesText sorts the messages of messages.yaml content by moving blocks of lines, as described by
+// sortMessages. It returns false if a paragraph holds more than one message, or the messages list isn't the last
+// top-level key.
+func sortMessagesText(content string) (string, bool) {
+	lines := strings.SplitAfter(strings.TrimRight(content, "\n")+"\n", "\n")
+	start := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "messages:") {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+
+	// Messages start with a list item at the indentation of the first one, which sets them apart from their args
+	itemPrefix := ""
+	for _, l := range lines[start:] {
+		if trimmed := strings.TrimSpace(l); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			itemPrefix = l[:len(l)-len(strings.TrimLeft(l, " "))] + "- "
+			break
+		}
+	}
+
+	type block struct {
+		code  string
+		lines []string
+	}
+	var blocks []block
+	var pending, paragraph []string
+	codeRegex := regexp.MustCompile(`^\s+code:\s*"?([^"\s]+)"?\s*$`)
+	endParagraph := func() bool {
+		if len(paragraph) == 0 {
+			return true
+		}
+		var b block
+		entries := 0
+		for _, l := range paragraph {
+			if strings.HasPrefix(l, itemPrefix) {
+				entries++
+			}
+			if m := codeRegex.FindStringSubmatch(l); m != nil && b.code == "" {
+				b.code = m[1]
+			}
+		}
+		if entries > 1 || (entries == 1 && b.code == "") {
+			return false
+		}
+		if entries == 0 {
+			for _, l := range paragraph {
+				if !strings.HasPrefix(strings.TrimSpace(l), "#") {
+					return false
+				}
+			}
+			// Comments on their own are kept with the message below them
+			pending = append(pending, paragraph...)
+			pending = append(pending, "\n")
+		} else {
+			b.lines = append(pending, paragraph...)
+			blocks = append(blocks, b)
+			pending = nil
+		}
+		paragraph = nil
+		return true
+	}
+	for _, l := range lines[start:] {
+		if strings.TrimSpace(l) == "" {
+			if !endParagraph() {
+				return "", false
+			}
+			continue
+		}
+		if !strings.HasPrefix(l, " ") && !strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "#") {
+			// Another top-level key
+			return "", false
+		}
+		paragraph = append(paragraph, l)
+	}
+	if !endParagraph() || len(blocks) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].code < blocks[j].code })
+
+	var out strings.Builder
+	for _, l := range lines[:start] {
+		out.WriteString(l)
+	}
+	for i, b := range blocks {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		for _, l := range b.lines {
+			out.WriteString(l)
+		}
+	}
+	if len(pending) > 0 {
+		out.WriteString("\n")
+		for _, l := range pending[:len(pending)-1] {
+			out.WriteString(l)
+		}
+	}
+	return out.String(), true
+}
+
+func read(path string) (*messages, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input file: %v", err)
+	}
+
+	m := &messages{}
+
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Enforce that names and codes follow expected regex and are unique. Arg type aliases are resolved in place.
+func validate(ms *messages) error {
+	// Checked before anything else, as an arg missing its name or type would otherwise be reported confusingly, or
+	// generate a constructor with a broken signature
+	for _, m := range ms.Messages {
+		for i, a := range m.Args {
+			if a.Name == "" || a.Type == "" {
+				return fmt.Errorf("Arg at index %d for message %q must have both a name and a type", i, m.Name)
+			}
+		}
+	}
+
+	if err := resolveArgTypes(ms); err != nil {
+		return err
+	}
+
+	codes := make(map[string]bool)
+	names := make(map[string]bool)
+	categories := make(map[string]bool)
+
+	codePattern := ms.codePattern()
+	codeRe, err := regexp.Compile(codePattern)
+	if err != nil {
+		return fmt.Errorf("Invalid codePattern %q: %v", codePattern, err)
+	}
+
+	// Templates using text/template syntax render their args with their String methods, so only printf verbs are
+	// restricted
+	allowed := make(map[string]bool)
+	var allowedList []string
+	for _, v := range strings.Split(*allowedVerbs, ",") {
+		v = strings.TrimSpace(v)
+		if !regexp.MustCompile("^"+verbRegex+"$").MatchString(v) || v == "%%" {
+			return fmt.Errorf("Invalid verb %q in -allowed-verbs", v)
+		}
+		allowed[v] = true
+		allowedList = append(allowedList, v)
+	}
+
+	for _, c := range ms.Categories {
+		if categories[c.Name] {
+			return fmt.Errorf("Category names must be unique, %q defined more than once", c.Name)
+		}
+		// Each category has a constant named after it
+		if !token.IsIdentifier("Category" + c.Name) {
+			return fmt.Errorf("Category name %q must be usable in a Go identifier", c.Name)
+		}
+		categories[c.Name] = true
+
+		if c.First < 0 || c.Last < c.First {
+			return fmt.Errorf("Category %q has an invalid code range %d-%d", c.Name, c.First, c.Last)
+		}
+		if last := formatCode(c.Last); !codeRe.MatchString(last) {
+			return fmt.Errorf("Category %q has codes up to %s, which do not follow the code regex %s", c.Name, last, codePattern)
+		}
+		for _, l := range c.Levels {
+			if !contains(diag.GetAllLevelStrings(), l) {
+				return fmt.Errorf("Category %q has an unknown level %q, expected one of %v", c.Name, l, diag.GetAllLevelStrings())
+			}
+		}
+	}
+
+	for _, m := range ms.Messages {
+		if !codeRe.MatchString(m.Code) {
+			return fmt.Errorf("Error code for message %q must follow the regex %s", m.Name, codePattern)
+		}
+
+		if len(ms.Categories) > 0 && categoryOf(ms, m.Code) == nil {
+			return fmt.Errorf("Error code for message %q is not in the range of any category", m.Name)
+		}
+
+		if codes[m.Code] {
+			return fmt.Errorf("Error codes must be unique, %q defined more than once", m.Code)
+		}
+		codes[m.Code] = true
+
+		matched, err := regexp.MatchString(nameRegex, m.Name)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return fmt.Errorf("Name for message %q must follow the regex %s", m.Name, nameRegex)
+		}
+
+		if names[m.Name] {
+			return fmt.Errorf("Message names must be unique, %q defined more than once", m.Name)
+		}
+		names[m.Name] = true
+
+		// Each message is generated as a variable named after it, alongside the rest of the package
+		if token.IsKeyword(m.Name) || reservedNames[m.Name] {
+			return fmt.Errorf("Name for message %q is reserved, as it would collide with a symbol of the msg package", m.Name)
+		}
+		if strings.HasPrefix(m.Name, "Category") && categories[strings.TrimPrefix(m.Name, "Category")] {
+			return fmt.Errorf("Name for message %q is reserved, as it would collide with the constant of category %q",
+				m.Name, strings.TrimPrefix(m.Name, "Category"))
+		}
+
+		if strings.TrimSpace(m.Template) == "" {
+			return fmt.Errorf("Template for message %q must not be empty", m.Name)
+		}
+
+		// Templates using text/template syntax refer to args by name, and must parse exactly as they will be for
+		// rendering. Printf templates consume them positionally, so the best that can be checked is that there is one
+		// verb per arg.
+		if strings.Contains(m.Template, "{{") {
+			if err := diag.ValidateTemplate(m.Template); err != nil {
+				return fmt.Errorf("Template for message %q is invalid: %v (available functions, in addition to the "+
+					"text/template builtins: %v)", m.Name, err, diag.TemplateFuncNames())
+			}
+			// Args are looked up by exact name, so a reference differing only in case fails when rendering
+			fields, _ := diag.TemplateFields(m.Template)
+			for _, f := range fields {
+				if err := checkArgReference(m, f); err != nil {
+					return err
+				}
+			}
+		}
+		// text/template treats a "}}" outside of any action as plain text, which is almost certainly a mistake, e.g. a
+		// misspelt "{{". Printf templates would print it as is too, so check both.
+		if text := regexp.MustCompile(actionRegex).ReplaceAllString(m.Template, ""); strings.Contains(text, "}}") {
+			return fmt.Errorf("Template for message %q has a \"}}\" without a matching \"{{\"", m.Name)
+		}
+		if !strings.Contains(m.Template, "{{") {
+			verbs := 0
+			for _, v := range regexp.MustCompile(verbRegex).FindAllString(m.Template, -1) {
+				if v == "%%" {
+					continue
+				}
+				// Verbs such as %v and %+v render structs with Go syntax, which shouldn't reach users
+				if !allowed[v] {
+					return fmt.Errorf("Template for message %q uses the verb %s, but only %s are allowed",
+						m.Name, v, strings.Join(allowedList, ", "))
+				}
+				verbs++
+			}
+			if verbs != len(m.Args) {
+				return fmt.Errorf("Template for message %q has %d verbs but %d args", m.Name, verbs, len(m.Args))
+			}
+		}
+
+		// Levels are often implied by code ranges, so a message at a level its category doesn't allow is likely to have
+		// been given the wrong code or level
+		if c := categoryOf(ms, m.Code); c != nil && len(c.Levels) > 0 && !contains(c.Levels, m.Level) {
+			return fmt.Errorf("Message %q has level %s, but messages in its category %q must have one of the levels %v",
+				m.Name, m.Level, c.Name, c.Levels)
+		}
+
+		if m.Url == "" {
+			if *requireURL {
+				return fmt.Errorf("Message %q must have a url", m.Name)
+			}
+			if c := categoryOf(ms, m.Code); c != nil && c.RequireURL {
+				return fmt.Errorf("Message %q must have a url, as required by its category %q", m.Name, c.Name)
+			}
+		}
+
+		if m.MinVersion != "" {
+			if _, err := version.NewVersion(m.MinVersion); err != nil {
+				return fmt.Errorf("Message %q has an invalid minVersion %q: %v", m.Name, m.MinVersion, err)
+			}
+		}
+
+		for i, e := range m.Examples {
+			if e.Bad == "" && e.Good == "" {
+				return fmt.Errorf("Example at index %d for message %q must have bad or good configuration", i, m.Name)
+			}
+		}
+
+		for _, a := range m.Args {
+			// Arg names become parameter names of the generated constructor, alongside the resource parameter "r"
+			if !token.IsIdentifier(a.Name) || a.Name == "r" {
+				return fmt.Errorf("Arg %q for message %q must be a valid Go identifier, and not a keyword or \"r\"", a.Name, m.Name)
+			}
+		}
+	}
+
+	// Aliases are former codes of messages, so can't be reused by any message, including those defined after them
+	for _, m := range ms.Messages {
+		for _, a := range m.Aliases {
+			if !codeRe.MatchString(a) {
+				return fmt.Errorf("Alias %q for message %q must follow the regex %s", a, m.Name, codePattern)
+			}
+			if codes[a] {
+				return fmt.Errorf("Alias %q for message %q is already used as a code or alias", a, m.Name)
+			}
+			codes[a] = true
+		}
+	}
+	return nil
+}
+
+// lint returns warnings about messages that are valid, but don't follow authoring conventions. Unlike validate, it
+// reports every issue it finds rather than stopping at the first.
+func lint(ms *messages) []string {
+	var warnings []string
+	templates := make(map[string]string)
+	for _, m := range ms.Messages {
+		if m.Url == "" {
+			warnings = append(warnings, fmt.Sprintf("Message %q has no url", m.Name))
+		}
+		if other, ok := templates[m.Template]; ok {
+			warnings = append(warnings, fmt.Sprintf("Message %q has the same template as %q", m.Name, other))
+		} else {
+			templates[m.Template] = m.Name
+		}
+	}
+	return warnings
+}
+
+// resolveArgTypes replaces references to the aliases in argTypes with the Go types they stand for, and checks that
+// every arg type resolves to a valid type. See checkArgType.
+func resolveArgTypes(ms *messages) error {
+	for name, path := range ms.Imports {
+		if !token.IsIdentifier(name) || path == "" {
+			return fmt.Errorf("Import %q must be a valid Go identifier with a non-empty import path", name)
+		}
+		if builtin, ok := builtinImports[name]; ok && builtin != path {
+			return fmt.Errorf("Import %q conflicts with the import of %s by the generated code", name, builtin)
+		}
+	}
+
+	for alias, typ := range ms.ArgTypes {
+		if !token.IsIdentifier(alias) || types.Universe.Lookup(alias) != nil {
+			return fmt.Errorf("Arg type alias %q must be a valid Go identifier, and not a predeclared Go type", alias)
+		}
+		if err := checkArgType(ms, typ); err != nil {
+			return fmt.Errorf("Arg type alias %q has an invalid type: %v", alias, err)
+		}
+	}
+
+	for i := range ms.Messages {
+		m := &ms.Messages[i]
+		for j := range m.Args {
+			a := &m.Args[j]
+			elem := strings.TrimPrefix(a.Type, "[]")
+			if typ, ok := ms.ArgTypes[elem]; ok {
+				a.Type = strings.TrimSuffix(a.Type, elem) + typ
+			}
+			if err := checkArgType(ms, a.Type); err != nil {
+				return fmt.Errorf("Arg %q for message %q has an invalid type: %v", a.Name, m.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkArgType returns an error unless typ is a predeclared Go type such as "string", or a type qualified by a
+// package the generated code imports such as "resource.Instance", or a slice of or pointer to one of those.
+func checkArgType(ms *messages, typ string) error {
+	pkg, name := splitArgType(typ)
+	if pkg == "" {
+		if _, ok := types.Universe.Lookup(name).(*types.TypeName); !ok {
+			return fmt.Errorf("%q is neither a predeclared Go type nor an alias in argTypes", typ)
+		}
+		return nil
+	}
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("%q is not a valid qualified type name", typ)
+	}
+	if _, ok := ms.importPath(pkg); !ok {
+		return fmt.Errorf("%q refers to package %q, which is not in imports", typ, pkg)
+	}
+	return nil
+}
+
+// splitArgType returns the package qualifier, if any, and the name of the element type of typ, e.g. "resource" and
+// "Instance" for "[]*resource.Instance".
+func splitArgType(typ string) (string, string) {
+	elem := strings.TrimLeft(typ, "[]*")
+	if i := strings.Index(elem, "."); i >= 0 {
+		return elem[:i], elem[i+1:]
+	}
+	return "", elem
+}
 
-// Utility for generating messages.gen.go. Called from gen.go
-func main() {
-	if len(os.Args) != 3 {
-		fmt.Println("Invalid args:", os.Args)
-		os.Exit(-1)
+// translations returns a gettext template (.pot) listing the template of each message for translation, with the
+// message code as context. The placeholders of each template are listed in a comment, since translations must keep
+// every one of them unchanged, although they may move them.
+func translations(ms *messages) string {
+	var b strings.Builder
+	b.WriteString("# Templates of the messages in messages.yaml, for translation. This file is generated, so don't edit it;\n")
+	b.WriteString("# instead copy it to <language>.po and fill in the msgstr of each message with its translation.\n")
+	b.WriteString("#\n")
+	b.WriteString("# Placeholders such as {{.detail}} or %v are filled in when a message is reported. Copy each of them into the\n")
+	b.WriteString("# translation exactly as written, without translating them.\n")
+	b.WriteString("msgid \"\"\n")
+	b.WriteString("msgstr \"\"\n")
+	b.WriteString("\"Content-Type: text/plain; charset=UTF-8\\n\"\n")
+
+	for _, m := range ms.Messages {
+		fmt.Fprintf(&b, "\n#. %s (%s)\n", m.Name, m.Code)
+		if p := placeholdersOf(m.Template); len(p) > 0 {
+			fmt.Fprintf(&b, "#. Placeholders, do not translate: %s\n", strings.Join(p, " "))
+		}
+		fmt.Fprintf(&b, "msgctxt %s\n", poQuote(m.Code))
+		fmt.Fprintf(&b, "msgid %s\n", poQuote(m.Template))
+		b.WriteString("msgstr \"\"\n")
 	}
+	return b.String()
+}
 
-	input := os.Args[1]
-	output := os.Args[2]
+// uncategorized is the heading of messages whose code isn't in the range of any category in the Markdown reference
+const uncategorized = "Uncategorized"
 
-	m, err := read(input)
-	if err != nil {
-		fmt.Println("Error reading metadata:", err)
-		os.Exit(-2)
+// markdown returns a reference of the messages as a Markdown table. If byCategory is set, the messages are instead
+// listed in a table per category, under a heading for each that is linked to from an index at the top. Categories
+// without any messages are left out, and messages outside of every category are listed last, as uncategorized.
+func markdown(ms *messages, byCategory bool) string {
+	var b strings.Builder
+	b.WriteString("# Configuration analysis messages\n\n")
+	b.WriteString("<!-- This file is generated from messages.yaml, so don't edit it. -->\n")
+
+	if !byCategory {
+		b.WriteString("\n")
+		markdownTable(&b, ms.Messages)
+		return b.String()
 	}
 
-	err = validate(m)
-	if err != nil {
-		fmt.Println("Error validating messages:", err)
-		os.Exit(-3)
+	var headings []string
+	grouped := make(map[string][]message)
+	for _, m := range ms.Messages {
+		heading := uncategorized
+		if c := categoryOf(ms, m.Code); c != nil {
+			heading = c.Name
+		}
+		if _, ok := grouped[heading]; !ok && heading != uncategorized {
+			headings = append(headings, heading)
+		}
+		grouped[heading] = append(grouped[heading], m)
+	}
+	// Categories are listed in the order they are defined, rather than that of their first message
+	sort.SliceStable(headings, func(i, j int) bool {
+		return categoryIndex(ms, headings[i]) < categoryIndex(ms, headings[j])
+	})
+	if _, ok := grouped[uncategorized]; ok {
+		headings = append(headings, uncategorized)
 	}
 
-	code, err := generate(m)
-	if err != nil {
-		fmt.Println("Error generating code:", err)
-		os.Exit(-4)
+	b.WriteString("\n")
+	for _, h := range headings {
+		fmt.Fprintf(&b, "- [%s](#%s) (%d)\n", h, markdownAnchor(h), len(grouped[h]))
 	}
+	for _, h := range headings {
+		fmt.Fprintf(&b, "\n## %s\n\n", h)
+		markdownTable(&b, grouped[h])
+	}
+	return b.String()
+}
 
-	if err = os.WriteFile(output, []byte(code), os.ModePerm); err != nil {
-		fmt.Println("Error writing output file:", err)
-		os.Exit(-5)
+// markdownTable writes a table of the given messages, linking each code to the documentation of the message.
+func markdownTable(b *strings.Builder, msgs []message) {
+	b.WriteString("| Code | Name | Level | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	escaper := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, m := range msgs {
+		code := m.Code
+		if m.Url != "" {
+			code = fmt.Sprintf("[%s](%s)", m.Code, m.Url)
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", code, m.Name, m.Level, escaper.Replace(m.Description))
 	}
 }
 
-func read(path string) (*messages, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read input file: %v", err)
+// deprecations returns a Markdown report of the deprecated messages and of the aliases of message codes, for release
+// notes and migration guides.
+func deprecations(ms *messages) string {
+	var b strings.Builder
+	b.WriteString("# Deprecated configuration analysis messages\n\n")
+	b.WriteString("<!-- This file is generated from messages.yaml, so don't edit it. -->\n")
+
+	escaper := strings.NewReplacer("|", `\|`, "\n", " ")
+	var deprecated, aliased []message
+	for _, m := range ms.Messages {
+		if m.Deprecated != "" {
+			deprecated = append(deprecated, m)
+		}
+		if len(m.Aliases) > 0 {
+			aliased = append(aliased, m)
+		}
 	}
 
-	m := &messages{}
+	b.WriteString("\n## Deprecated messages\n\n")
+	if len(deprecated) == 0 {
+		b.WriteString("No messages are deprecated.\n")
+	} else {
+		b.WriteString("| Code | Name | Deprecation |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, m := range deprecated {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", m.Code, m.Name, escaper.Replace(m.Deprecated))
+		}
+	}
 
-	if err := yaml.Unmarshal(b, m); err != nil {
-		return nil, err
+	b.WriteString("\n## Aliases\n\n")
+	if len(aliased) == 0 {
+		b.WriteString("No codes have aliases.\n")
+	} else {
+		b.WriteString("| Alias | Code | Name |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, m := range aliased {
+			for _, a := range m.Aliases {
+				fmt.Fprintf(&b, "| %s | %s | %s |\n", a, m.Code, m.Name)
+			}
+		}
 	}
+	return b.String()
+}
 
-	return m, nil
+// markdownAnchor returns the anchor of a Markdown heading as generated by GitHub: lower case, with spaces replaced by
+// hyphens and other punctuation removed.
+func markdownAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
 }
 
-// Enforce that names and codes follow expected regex and are unique
-func validate(ms *messages) error {
-	codes := make(map[string]bool)
-	names := make(map[string]bool)
+// categoryIndex returns the position of the named category in messages.yaml, or -1 if there is none.
+func categoryIndex(ms *messages, name string) int {
+	for i, c := range ms.Categories {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
 
-	for _, m := range ms.Messages {
-		matched, err := regexp.MatchString(codeRegex, m.Code)
-		if err != nil {
-			return err
+// checkArgReference returns an error if the template of a message refers to a field that isn't one of its args,
+// suggesting an arg whose name only differs in case.
+func checkArgReference(m message, field string) error {
+	var names []string
+	for _, a := range m.Args {
+		if a.Name == field {
+			return nil
 		}
-		if !matched {
-			return fmt.Errorf("Error code for message %q must follow the regex %s", m.Name, codeRegex)
+		if strings.EqualFold(a.Name, field) {
+			return fmt.Errorf("Template for message %q refers to %q, which is not an arg, did you mean %q?", m.Name, field, a.Name)
 		}
+		names = append(names, a.Name)
+	}
+	return fmt.Errorf("Template for message %q refers to %q, which is not an arg (args: %v)", m.Name, field, names)
+}
 
-		if codes[m.Code] {
-			return fmt.Errorf("Error codes must be unique, %q defined more than once", m.Code)
+// contains returns whether s is one of the given values
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
 		}
-		codes[m.Code] = true
+	}
+	return false
+}
 
-		matched, err = regexp.MatchString(nameRegex, m.Name)
-		if err != nil {
-			return err
+// placeholdersOf returns the placeholders of a template in the order they appear: the actions of templates using
+// text/template syntax, or the verbs of printf templates.
+func placeholdersOf(tmpl string) []string {
+	if strings.Contains(tmpl, "{{") {
+		return regexp.MustCompile(actionRegex).FindAllString(tmpl, -1)
+	}
+	var result []string
+	for _, v := range regexp.MustCompile(verbRegex).FindAllString(tmpl, -1) {
+		if v != "%%" {
+			result = append(result, v)
 		}
-		if !matched {
-			return fmt.Errorf("Name for message %q must follow the regex %s", m.Name, nameRegex)
+	}
+	return result
+}
+
+// codeMap returns the code and name of each message, one per line in order of code, e.g. "IST0101 ReferencedResourceNotFound".
+func codeMap(m *messages) string {
+	sorted := append([]message(nil), m.Messages...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Code < sorted[j].Code })
+
+	var b strings.Builder
+	b.WriteString("# GENERATED FILE -- DO NOT EDIT\n")
+	b.WriteString("# The name of the message with each code. Changes to existing lines mean a code has been reassigned.\n")
+	for _, msg := range sorted {
+		fmt.Fprintf(&b, "%s %s\n", msg.Code, msg.Name)
+	}
+	return b.String()
+}
+
+// poQuote quotes a string for a gettext file
+func poQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
+
+// extraImports returns the import specs, beyond those always imported, needed by the arg types of the messages.
+func extraImports(ms *messages) []string {
+	specs := make(map[string]bool)
+	for _, m := range ms.Messages {
+		for _, a := range m.Args {
+			pkg, _ := splitArgType(a.Type)
+			if _, ok := builtinImports[pkg]; pkg == "" || ok {
+				continue
+			}
+			path, _ := ms.importPath(pkg)
+			spec := fmt.Sprintf("%q", path)
+			if pathpkg.Base(path) != pkg {
+				spec = pkg + " " + spec
+			}
+			specs[spec] = true
 		}
+	}
 
-		if names[m.Name] {
-			return fmt.Errorf("Message names must be unique, %q defined more than once", m.Name)
+	var result []string
+	for spec := range specs {
+		result = append(result, spec)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// nextCode returns the lowest code within the range of the named category that is not used by any message.
+func nextCode(ms *messages, categoryName string) (string, error) {
+	var cat *category
+	for i := range ms.Categories {
+		if ms.Categories[i].Name == categoryName {
+			cat = &ms.Categories[i]
+			break
+		}
+	}
+	if cat == nil {
+		return "", fmt.Errorf("unknown category %q", categoryName)
+	}
+
+	used := make(map[string]bool)
+	for _, m := range ms.Messages {
+		used[m.Code] = true
+		for _, a := range m.Aliases {
+			used[a] = true
+		}
+	}
+
+	for n := cat.First; n <= cat.Last; n++ {
+		code := formatCode(n)
+		if !used[code] {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("no unused codes left in category %q", categoryName)
+}
+
+// formatCode returns the code with the given number, zero padded to at least four digits.
+func formatCode(n int) string {
+	return fmt.Sprintf("IST%04d", n)
+}
+
+// categoryOf returns the category whose range includes the given code, or nil if there is none.
+func categoryOf(ms *messages, code string) *category {
+	var n int
+	if _, err := fmt.Sscanf(code, "IST%d", &n); err != nil {
+		return nil
+	}
+	for i := range ms.Categories {
+		if n >= ms.Categories[i].First && n <= ms.Categories[i].Last {
+			return &ms.Categories[i]
 		}
-		names[m.Name] = true
 	}
 	return nil
 }
@@ -122,18 +1197,72 @@ var tmpl = `
 package msg
 
 import (
+	{{- if usesType . "error"}}
+	"errors"
+	{{end}}
 	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/pkg/config/resource"
+	{{- range extraImports .}}
+	{{.}}
+	{{- end}}
 )
 
 var (
 	{{- range .Messages}}
 	// {{.Name}} defines a diag.MessageType for message "{{.Name}}".
 	// Description: {{.Description}}
-	{{.Name}} = diag.NewMessageType(diag.{{.Level}}, "{{.Code}}", "{{.Template}}")
+	{{- if .Deprecated}}
+	//
+	// Deprecated: {{.Deprecated}}
+	{{- end}}
+	{{.Name}} = diag.NewMessageType(diag.{{.Level}}, "{{.Code}}", {{printf "%q" .Template}},
+		diag.WithName("{{.Name}}"),
+		{{- with categoryName .Code}}
+		diag.WithCategory(string(Category{{.}})),
+		{{- end}}
+		diag.WithDescription({{printf "%q" .Description}}),
+		{{- if .LongDescription}}
+		diag.WithLongDescription({{printf "%q" .LongDescription}}),
+		{{- end}}
+		diag.WithURL({{printf "%q" .Url}}),
+		{{- if .MinVersion}}
+		diag.WithMinVersion({{printf "%q" .MinVersion}}),
+		{{- end}}
+		{{- if .Autofix}}
+		diag.WithAutofix(),
+		{{- end}}
+		{{- if .Args}}
+		diag.WithArgs({{range $i, $a := .Args}}{{if $i}}, {{end}}"{{$a.Name}}"{{end}}),
+		{{- end}}
+	)
 	{{end}}
 )
 
+// Category is a category of message types, as declared in messages.yaml.
+type Category string
+
+const (
+	{{- range .Categories}}
+	// Category{{.Name}} is the category of messages with codes {{formatCode .First}} to {{formatCode .Last}}.
+	Category{{.Name}} Category = "{{.Name}}"
+	{{- end}}
+)
+
+// Categories returns all message categories, in the order they are declared.
+func Categories() []Category {
+	return []Category{
+		{{- range .Categories}}
+		Category{{.Name}},
+		{{- end}}
+	}
+}
+
+// CategoryOf returns the category of a message type, which is empty for message types not generated from
+// messages.yaml that don't declare one.
+func CategoryOf(mt *diag.MessageType) Category {
+	return Category(mt.Category())
+}
+
 // All returns a list of all known message types.
 func All() []*diag.MessageType {
 	return []*diag.MessageType{
@@ -143,6 +1272,59 @@ func All() []*diag.MessageType {
 	}
 }
 
+func init() {
+	for _, mt := range All() {
+		if err := diag.RegisterMessageType(mt); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ForCode returns the message type with the given code, or nil if there is none. This includes message types
+// registered at runtime with diag.RegisterMessageType, as well as those listed by All.
+func ForCode(code string) *diag.MessageType {
+	mt, _ := diag.LookupMessageType(code)
+	return mt
+}
+
+// Constructors returns the arguments of each generated message constructor, in order and keyed by constructor name.
+// The leading *resource.Instance argument shared by every constructor is omitted. It is intended for tooling that
+// checks calls to the constructors.
+func Constructors() map[string][]ArgInfo {
+	return map[string][]ArgInfo{
+		{{- range .Messages}}
+		"New{{.Name}}": {
+			{{- range $i, $a := .Args}}{{if $i}}, {{end}}{Name: "{{$a.Name}}", Type: "{{$a.Type}}"}{{end -}}
+		},
+		{{- end}}
+	}
+}
+
+{{- if metrics}}
+
+// MetricDescriptors returns a descriptor of a metric counting the messages of each type listed by All, in the same
+// order, so that metrics exporters can register them.
+func MetricDescriptors() []MetricDescriptor {
+	return []MetricDescriptor{
+		{{- range .Messages}}
+		{Name: "{{metricName .Code}}", Help: {{printf "%q" .Description}}, Code: "{{.Code}}"},
+		{{- end}}
+	}
+}
+{{- end}}
+
+// SampleMessages returns one instance of each known message type, with deterministic placeholder values filled in for
+// the arguments: "sample-string" for strings, zero for numbers, false for bools, an error with the text "sample-error"
+// for errors, a single placeholder element for slices and the zero value for any other type. It is intended for use as
+// a fixture when testing formatters, as the rendered messages are the same on every call.
+func SampleMessages() diag.Messages {
+	return diag.Messages{
+		{{- range .Messages}}
+			New{{.Name}}(nil{{range .Args}}, {{placeholder .Type}}{{end}}),
+		{{- end}}
+	}
+}
+
 {{range .Messages}}
 // New{{.Name}} returns a new diag.Message based on {{.Name}}.
 func New{{.Name}}(r *resource.Instance{{range .Args}}, {{.Name}} {{.Type}}{{end}}) diag.Message {
@@ -158,7 +1340,33 @@ func New{{.Name}}(r *resource.Instance{{range .Args}}, {{.Name}} {{.Type}}{{end}
 `
 
 func generate(m *messages) (string, error) {
-	t := template.Must(template.New("code").Parse(tmpl))
+	for _, msg := range m.Messages {
+		for _, a := range msg.Args {
+			if _, err := placeholder(a.Type); err != nil {
+				return "", fmt.Errorf("unable to generate sample for message %q: %v", msg.Name, err)
+			}
+		}
+	}
+
+	t := template.Must(template.New("code").Funcs(template.FuncMap{
+		"placeholder": func(typ string) string {
+			p, _ := placeholder(typ)
+			return p
+		},
+		"usesType":     usesType,
+		"extraImports": extraImports,
+		"metricName":   metricName,
+		"formatCode":   formatCode,
+		"metrics": func() bool {
+			return *metrics
+		},
+		"categoryName": func(code string) string {
+			if c := categoryOf(m, code); c != nil {
+				return c.Name
+			}
+			return ""
+		},
+	}).Parse(tmpl))
 
 	var b bytes.Buffer
 	if err := t.Execute(&b, m); err != nil {
@@ -167,18 +1375,328 @@ func generate(m *messages) (string, error) {
 	return b.String(), nil
 }
 
+// shimTmpl generates a package in the old location of the messages, which forwards to the package they have moved to
+// so that existing importers keep building while they migrate.
+var shimTmpl = `
+// GENERATED FILE -- DO NOT EDIT
+//
+
+// Package msg forwards to the messages, which have moved to {{.Path}}.
+//
+// Deprecated: use {{.Path}} instead.
+package msg
+
+import (
+	moved "{{.Path}}"
+
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/resource"
+	{{- range extraImports .Messages}}
+	{{.}}
+	{{- end}}
+)
+
+var (
+	{{- range .Messages.Messages}}
+	// {{.Name}} defines a diag.MessageType for message "{{.Name}}".
+	//
+	// Deprecated: use {{$.Path}}.{{.Name}} instead.
+	{{.Name}} = moved.{{.Name}}
+	{{end}}
+)
+
+{{range .Messages.Messages}}
+// New{{.Name}} returns a new diag.Message based on {{.Name}}.
+//
+// Deprecated: use {{$.Path}}.New{{.Name}} instead.
+func New{{.Name}}(r *resource.Instance{{range .Args}}, {{.Name}} {{.Type}}{{end}}) diag.Message {
+	return moved.New{{.Name}}(r{{range .Args}}, {{.Name}}{{end}})
+}
+{{end}}
+`
+
+// generateShim returns the code of a deprecated package re-exporting every message type and constructor from the
+// package at importPath, to which the messages have moved.
+func generateShim(m *messages, importPath string) (string, error) {
+	t := template.Must(template.New("shim").Funcs(template.FuncMap{
+		"extraImports": extraImports,
+	}).Parse(shimTmpl))
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, struct {
+		Path     string
+		Messages *messages
+	}{importPath, m}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// examplesTmpl generates godoc examples of calling message constructors and rendering the messages they return.
+var examplesTmpl = `
+// GENERATED FILE -- DO NOT EDIT
+//
+
+package msg
+
+import (
+	"fmt"
+)
+{{range .}}
+func ExampleNew{{.Name}}() {
+	m := New{{.Name}}(nil{{range .Args}}, {{.}}{{end}})
+	fmt.Println(m.String())
+	// Output:
+	{{- range .Output}}
+	// {{.}}
+	{{- end}}
+}
+{{end}}`
+
+// exampleValues are the values of args of each type in generated examples, which are the values of the placeholders
+// of the type. Messages with args of other types aren't used as examples.
+var exampleValues = map[string]interface{}{
+	"string": "sample-string",
+	"int":    0,
+	"int32":  int32(0),
+	"bool":   false,
+}
+
+// godocExample is an Example function generated for a message constructor
+type godocExample struct {
+	Name   string
+	Args   []string
+	Output []string
+}
+
+// generateExamples returns the code of godoc examples for a few representative messages: the first message of each
+// category and of each level, among those with args of the types in exampleValues. The output of each example is the
+// message rendered with the template as it is in messages.yaml. The code is gofmt-ed, so that it matches the committed
+// file without a separate formatting step.
+func generateExamples(m *messages) (string, error) {
+	var examples []godocExample
+	seenCategories := make(map[*category]bool)
+	seenLevels := make(map[string]bool)
+	for _, msg := range m.Messages {
+		c := categoryOf(m, msg.Code)
+		if seenCategories[c] && seenLevels[msg.Level] {
+			continue
+		}
+
+		var args, names []string
+		var values []interface{}
+		for _, a := range msg.Args {
+			v, ok := exampleValues[a.Type]
+			if !ok {
+				break
+			}
+			p, _ := placeholder(a.Type)
+			args = append(args, p)
+			names = append(names, a.Name)
+			values = append(values, v)
+		}
+		if len(values) != len(msg.Args) {
+			continue
+		}
+
+		level, ok := diag.GetUppercaseStringToLevelMap()[strings.ToUpper(msg.Level)]
+		if !ok {
+			return "", fmt.Errorf("message %q has an unknown level %q", msg.Name, msg.Level)
+		}
+		mt := diag.NewMessageType(level, msg.Code, msg.Template, diag.WithArgs(names...))
+		rendered := diag.NewMessage(mt, nil, values...)
+		examples = append(examples, godocExample{
+			Name:   msg.Name,
+			Args:   args,
+			Output: strings.Split(rendered.String(), "\n"),
+		})
+		seenCategories[c] = true
+		seenLevels[msg.Level] = true
+	}
+
+	t := template.Must(template.New("examples").Parse(examplesTmpl))
+	var b bytes.Buffer
+	if err := t.Execute(&b, examples); err != nil {
+		return "", err
+	}
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("generated examples are not valid Go: %v", err)
+	}
+	return string(formatted), nil
+}
+
+// metricName returns the name of the metric counting messages with the given code, replacing any characters not
+// allowed in Prometheus metric names with underscores.
+func metricName(code string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, code)
+	return "istio_analysis_" + sanitized + "_total"
+}
+
+// validateMetricNames returns an error if the codes of two messages map to the same metric name.
+func validateMetricNames(ms *messages) error {
+	names := make(map[string]string)
+	for _, m := range ms.Messages {
+		name := metricName(m.Code)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("Codes %q and %q both have the metric name %q", other, m.Code, name)
+		}
+		names[name] = m.Code
+	}
+	return nil
+}
+
+// reservedNames are the package-level symbols of the msg package that messages can't be named after, both those
+// generated from tmpl and those declared in the other files of the package. Keep it in sync with them.
+var reservedNames = map[string]bool{
+	// Generated
+	"All":               true,
+	"Categories":        true,
+	"Category":          true,
+	"CategoryOf":        true,
+	"Constructors":      true,
+	"ForCode":           true,
+	"MetricDescriptors": true,
+	"SampleMessages":    true,
+	// Declared in messages.go and examples.go
+	"ArgInfo":          true,
+	"Example":          true,
+	"ExamplesFor":      true,
+	"MetricDescriptor": true,
+}
+
+// builtinImports are the packages always imported by the generated code, keyed by package name
+var builtinImports = map[string]string{
+	"diag":     "istio.io/istio/galley/pkg/config/analysis/diag",
+	"resource": "istio.io/istio/pkg/config/resource",
+}
+
+// placeholders maps arg types to the Go expressions used as their values in SampleMessages. They are fixed rather than
+// random, so that rendered samples can be used in golden files. Keep the list in messages.yaml in sync.
+var placeholders = map[string]string{
+	"string":  `"sample-string"`,
+	"int":     "0",
+	"int8":    "0",
+	"int16":   "0",
+	"int32":   "0",
+	"int64":   "0",
+	"uint":    "0",
+	"uint8":   "0",
+	"uint16":  "0",
+	"uint32":  "0",
+	"uint64":  "0",
+	"float32": "0",
+	"float64": "0",
+	"byte":    "0",
+	"rune":    "0",
+	"bool":    "false",
+	"error":   `errors.New("sample-error")`,
+}
+
+// placeholder returns a deterministic Go expression of the given type, for use in generated samples.
+func placeholder(typ string) (string, error) {
+	if strings.HasPrefix(typ, "[]") {
+		elem, err := placeholder(strings.TrimPrefix(typ, "[]"))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s{%s}", typ, elem), nil
+	}
+	if pkg, _ := splitArgType(typ); pkg != "" || strings.HasPrefix(typ, "*") {
+		// Any other type is represented by its zero value
+		return fmt.Sprintf("*new(%s)", typ), nil
+	}
+	p, ok := placeholders[typ]
+	if !ok {
+		return "", fmt.Errorf("no placeholder value known for type %q", typ)
+	}
+	return p, nil
+}
+
+// usesType returns true if any message declares an arg of the given type, or a slice of it.
+func usesType(ms *messages, typ string) bool {
+	for _, m := range ms.Messages {
+		for _, a := range m.Args {
+			if strings.TrimPrefix(a.Type, "[]") == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type messages struct {
-	Messages []message `json:"messages"`
+	// The regex codes must follow, overriding codeRegex. Widen this deliberately when a category runs out of codes.
+	CodePattern string `json:"codePattern,omitempty"`
+
+	// Packages that arg types may refer to, mapping package names to import paths. The diag and resource packages are
+	// always available.
+	Imports map[string]string `json:"imports,omitempty"`
+
+	// Aliases for arg types, mapping logical names such as "host" to the Go types they stand for
+	ArgTypes map[string]string `json:"argTypes,omitempty"`
+
+	Categories []category `json:"categories"`
+	Messages   []message  `json:"messages"`
+}
+
+// importPath returns the import path of the named package, if arg types may refer to it.
+func (ms *messages) importPath(pkg string) (string, bool) {
+	if path, ok := builtinImports[pkg]; ok {
+		return path, true
+	}
+	path, ok := ms.Imports[pkg]
+	return path, ok
+}
+
+func (ms *messages) codePattern() string {
+	if ms.CodePattern != "" {
+		return ms.CodePattern
+	}
+	return codeRegex
+}
+
+type category struct {
+	Name string `json:"name"`
+
+	// The range of codes, inclusive, allocated to the category
+	First int `json:"first"`
+	Last  int `json:"last"`
+
+	// Whether every message in the category must have a url
+	RequireURL bool `json:"requireUrl"`
+
+	// The levels messages in the category may have, e.g. only Error for a category of errors. Any level is allowed if
+	// empty.
+	Levels []string `json:"levels,omitempty"`
 }
 
 type message struct {
-	Name        string `json:"name"`
-	Code        string `json:"code"`
-	Level       string `json:"level"`
+	Name            string    `json:"name"`
+	Code            string    `json:"code"`
+	Level           string    `json:"level"`
+	Description     string    `json:"description"`
+	LongDescription string    `json:"longDescription,omitempty"`
+	Template        string    `json:"template"`
+	Url             string    `json:"url"`
+	MinVersion      string    `json:"minVersion,omitempty"`
+	Autofix         bool      `json:"autofix,omitempty"`
+	Deprecated      string    `json:"deprecated,omitempty"`
+	Aliases         []string  `json:"aliases,omitempty"`
+	Examples        []example `json:"examples,omitempty"`
+	Args            []arg     `json:"args"`
+}
+
+// example is an example of configuration the message is about, as Example in messages.go
+type example struct {
 	Description string `json:"description"`
-	Template    string `json:"template"`
-	Url         string `json:"url"`
-	Args        []arg  `json:"args"`
+	Bad         string `json:"bad,omitempty"`
+	Good        string `json:"good,omitempty"`
 }
 
 type arg struct {
@@ -0,0 +1,66 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestGenerate_ErrorSliceImportsErrors runs the generator on messages whose only error arg is a slice of errors, whose
+// placeholder in SampleMessages still needs the errors package.
+func TestGenerate_ErrorSliceImportsErrors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator with go run")
+	}
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "messages.yaml")
+	output := filepath.Join(dir, "messages.gen.go")
+	g.Expect(os.WriteFile(input, []byte(`categories:
+  - name: "Analysis"
+    first: 101
+    last: 9999
+
+messages:
+  - name: "MultipleFailures"
+    code: IST0101
+    level: Error
+    description: "Several things failed."
+    template: "Failures: %s"
+    url: "https://istio.io/latest/docs/reference/config/analysis/ist0101/"
+    args:
+      - name: errs
+        type: "[]error"
+`), 0o644)).To(Succeed())
+
+	out, err := exec.Command("go", "run", "generate.main.go", input, output).CombinedOutput()
+	g.Expect(err).To(BeNil(), string(out))
+
+	f, err := parser.ParseFile(token.NewFileSet(), output, nil, parser.ImportsOnly)
+	g.Expect(err).To(BeNil())
+	var imports []string
+	for _, i := range f.Imports {
+		imports = append(imports, i.Path.Value)
+	}
+	g.Expect(imports).To(ContainElement(`"errors"`))
+}
@@ -4,6 +4,8 @@
 package msg
 
 import (
+	"errors"
+
 	"istio.io/istio/galley/pkg/config/analysis/diag"
 	"istio.io/istio/pkg/config/resource"
 )
@@ -11,185 +13,475 @@ import (
 var (
 	// InternalError defines a diag.MessageType for message "InternalError".
 	// Description: There was an internal error in the toolchain. This is almost always a bug in the implementation.
-	InternalError = diag.NewMessageType(diag.Error, "IST0001", "Internal error: %v")
+	InternalError = diag.NewMessageType(diag.Error, "IST0001", "Internal error: {{.detail}}",
+		diag.WithName("InternalError"),
+		diag.WithCategory(string(CategoryInternal)),
+		diag.WithDescription("There was an internal error in the toolchain. This is almost always a bug in the implementation."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0001/"),
+		diag.WithArgs("detail"),
+	)
 
 	// Deprecated defines a diag.MessageType for message "Deprecated".
 	// Description: A feature that the configuration is depending on is now deprecated.
-	Deprecated = diag.NewMessageType(diag.Warning, "IST0002", "Deprecated: %s")
+	Deprecated = diag.NewMessageType(diag.Warning, "IST0002", "Deprecated: {{.detail}}",
+		diag.WithName("Deprecated"),
+		diag.WithCategory(string(CategoryInternal)),
+		diag.WithDescription("A feature that the configuration is depending on is now deprecated."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0002/"),
+		diag.WithArgs("detail"),
+	)
 
 	// ReferencedResourceNotFound defines a diag.MessageType for message "ReferencedResourceNotFound".
 	// Description: A resource being referenced does not exist.
-	ReferencedResourceNotFound = diag.NewMessageType(diag.Error, "IST0101", "Referenced %s not found: %q")
+	ReferencedResourceNotFound = diag.NewMessageType(diag.Error, "IST0101", "Referenced {{.reftype}} not found: {{quote .refval}}",
+		diag.WithName("ReferencedResourceNotFound"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A resource being referenced does not exist."),
+		diag.WithLongDescription("A resource being referenced does not exist. Create the referenced resource, or correct the reference if it has a typo or is in the wrong namespace."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0101/"),
+		diag.WithArgs("reftype", "refval"),
+	)
 
 	// NamespaceNotInjected defines a diag.MessageType for message "NamespaceNotInjected".
 	// Description: A namespace is not enabled for Istio injection.
-	NamespaceNotInjected = diag.NewMessageType(diag.Info, "IST0102", "The namespace is not enabled for Istio injection. Run 'kubectl label namespace %s istio-injection=enabled' to enable it, or 'kubectl label namespace %s istio-injection=disabled' to explicitly mark it as not needing injection.")
+	NamespaceNotInjected = diag.NewMessageType(diag.Info, "IST0102", "The namespace is not enabled for Istio injection. Run 'kubectl label namespace {{.namespace}} istio-injection=enabled' to enable it, or 'kubectl label namespace {{.namespace2}} istio-injection=disabled' to explicitly mark it as not needing injection.",
+		diag.WithName("NamespaceNotInjected"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A namespace is not enabled for Istio injection."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0102/"),
+		diag.WithArgs("namespace", "namespace2"),
+	)
 
 	// PodMissingProxy defines a diag.MessageType for message "PodMissingProxy".
 	// Description: A pod is missing the Istio proxy.
-	PodMissingProxy = diag.NewMessageType(diag.Warning, "IST0103", "The pod is missing the Istio proxy. This can often be resolved by restarting or redeploying the workload.")
+	PodMissingProxy = diag.NewMessageType(diag.Warning, "IST0103", "The pod is missing the Istio proxy. This can often be resolved by restarting or redeploying the workload.",
+		diag.WithName("PodMissingProxy"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A pod is missing the Istio proxy."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0103/"),
+	)
 
 	// GatewayPortNotOnWorkload defines a diag.MessageType for message "GatewayPortNotOnWorkload".
 	// Description: Unhandled gateway port
-	GatewayPortNotOnWorkload = diag.NewMessageType(diag.Warning, "IST0104", "The gateway refers to a port that is not exposed on the workload (pod selector %s; port %d)")
+	GatewayPortNotOnWorkload = diag.NewMessageType(diag.Warning, "IST0104", "The gateway refers to a port that is not exposed on the workload (pod selector {{.selector}}; port {{.port}})",
+		diag.WithName("GatewayPortNotOnWorkload"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Unhandled gateway port"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0104/"),
+		diag.WithArgs("selector", "port"),
+	)
 
 	// IstioProxyImageMismatch defines a diag.MessageType for message "IstioProxyImageMismatch".
 	// Description: The image of the Istio proxy running on the pod does not match the image defined in the injection configuration.
-	IstioProxyImageMismatch = diag.NewMessageType(diag.Warning, "IST0105", "The image of the Istio proxy running on the pod does not match the image defined in the injection configuration (pod image: %s; injection configuration image: %s). This often happens after upgrading the Istio control-plane and can be fixed by redeploying the pod.")
+	IstioProxyImageMismatch = diag.NewMessageType(diag.Warning, "IST0105", "The image of the Istio proxy running on the pod does not match the image defined in the injection configuration (pod image: {{.proxyImage}}; injection configuration image: {{.injectionImage}}). This often happens after upgrading the Istio control-plane and can be fixed by redeploying the pod.",
+		diag.WithName("IstioProxyImageMismatch"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("The image of the Istio proxy running on the pod does not match the image defined in the injection configuration."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0105/"),
+		diag.WithArgs("proxyImage", "injectionImage"),
+	)
 
 	// SchemaValidationError defines a diag.MessageType for message "SchemaValidationError".
 	// Description: The resource has a schema validation error.
-	SchemaValidationError = diag.NewMessageType(diag.Error, "IST0106", "Schema validation error: %v")
+	SchemaValidationError = diag.NewMessageType(diag.Error, "IST0106", "Schema validation error: {{.err}}",
+		diag.WithName("SchemaValidationError"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("The resource has a schema validation error."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0106/"),
+		diag.WithArgs("err"),
+	)
 
 	// MisplacedAnnotation defines a diag.MessageType for message "MisplacedAnnotation".
 	// Description: An Istio annotation is applied to the wrong kind of resource.
-	MisplacedAnnotation = diag.NewMessageType(diag.Warning, "IST0107", "Misplaced annotation: %s can only be applied to %s")
+	MisplacedAnnotation = diag.NewMessageType(diag.Warning, "IST0107", "Misplaced annotation: {{.annotation}} can only be applied to {{.kind}}",
+		diag.WithName("MisplacedAnnotation"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("An Istio annotation is applied to the wrong kind of resource."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0107/"),
+		diag.WithArgs("annotation", "kind"),
+	)
 
 	// UnknownAnnotation defines a diag.MessageType for message "UnknownAnnotation".
 	// Description: An Istio annotation is not recognized for any kind of resource
-	UnknownAnnotation = diag.NewMessageType(diag.Warning, "IST0108", "Unknown annotation: %s")
+	UnknownAnnotation = diag.NewMessageType(diag.Warning, "IST0108", "Unknown annotation: {{.annotation}}",
+		diag.WithName("UnknownAnnotation"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("An Istio annotation is not recognized for any kind of resource"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0108/"),
+		diag.WithArgs("annotation"),
+	)
 
 	// ConflictingMeshGatewayVirtualServiceHosts defines a diag.MessageType for message "ConflictingMeshGatewayVirtualServiceHosts".
 	// Description: Conflicting hosts on VirtualServices associated with mesh gateway
-	ConflictingMeshGatewayVirtualServiceHosts = diag.NewMessageType(diag.Error, "IST0109", "The VirtualServices %s associated with mesh gateway define the same host %s which can lead to undefined behavior. This can be fixed by merging the conflicting VirtualServices into a single resource.")
+	ConflictingMeshGatewayVirtualServiceHosts = diag.NewMessageType(diag.Error, "IST0109", "The VirtualServices {{.virtualServices}} associated with mesh gateway define the same host {{.host}} which can lead to undefined behavior. This can be fixed by merging the conflicting VirtualServices into a single resource.",
+		diag.WithName("ConflictingMeshGatewayVirtualServiceHosts"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Conflicting hosts on VirtualServices associated with mesh gateway"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0109/"),
+		diag.WithArgs("virtualServices", "host"),
+	)
 
 	// ConflictingSidecarWorkloadSelectors defines a diag.MessageType for message "ConflictingSidecarWorkloadSelectors".
 	// Description: A Sidecar resource selects the same workloads as another Sidecar resource
-	ConflictingSidecarWorkloadSelectors = diag.NewMessageType(diag.Error, "IST0110", "The Sidecars %v in namespace %q select the same workload pod %q, which can lead to undefined behavior.")
+	ConflictingSidecarWorkloadSelectors = diag.NewMessageType(diag.Error, "IST0110", "The Sidecars {{.conflictingSidecars}} in namespace {{quote .namespace}} select the same workload pod {{quote .workloadPod}}, which can lead to undefined behavior.",
+		diag.WithName("ConflictingSidecarWorkloadSelectors"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A Sidecar resource selects the same workloads as another Sidecar resource"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0110/"),
+		diag.WithArgs("conflictingSidecars", "namespace", "workloadPod"),
+	)
 
 	// MultipleSidecarsWithoutWorkloadSelectors defines a diag.MessageType for message "MultipleSidecarsWithoutWorkloadSelectors".
 	// Description: More than one sidecar resource in a namespace has no workload selector
-	MultipleSidecarsWithoutWorkloadSelectors = diag.NewMessageType(diag.Error, "IST0111", "The Sidecars %v in namespace %q have no workload selector, which can lead to undefined behavior.")
+	MultipleSidecarsWithoutWorkloadSelectors = diag.NewMessageType(diag.Error, "IST0111", "The Sidecars {{.conflictingSidecars}} in namespace {{quote .namespace}} have no workload selector, which can lead to undefined behavior.",
+		diag.WithName("MultipleSidecarsWithoutWorkloadSelectors"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("More than one sidecar resource in a namespace has no workload selector"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0111/"),
+		diag.WithArgs("conflictingSidecars", "namespace"),
+	)
 
 	// VirtualServiceDestinationPortSelectorRequired defines a diag.MessageType for message "VirtualServiceDestinationPortSelectorRequired".
 	// Description: A VirtualService routes to a service with more than one port exposed, but does not specify which to use.
-	VirtualServiceDestinationPortSelectorRequired = diag.NewMessageType(diag.Error, "IST0112", "This VirtualService routes to a service %q that exposes multiple ports %v. Specifying a port in the destination is required to disambiguate.")
+	VirtualServiceDestinationPortSelectorRequired = diag.NewMessageType(diag.Error, "IST0112", "This VirtualService routes to a service {{quote .destHost}} that exposes multiple ports {{.destPorts}}. Specifying a port in the destination is required to disambiguate.",
+		diag.WithName("VirtualServiceDestinationPortSelectorRequired"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A VirtualService routes to a service with more than one port exposed, but does not specify which to use."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0112/"),
+		diag.WithArgs("destHost", "destPorts"),
+	)
 
 	// MTLSPolicyConflict defines a diag.MessageType for message "MTLSPolicyConflict".
 	// Description: A DestinationRule and Policy are in conflict with regards to mTLS.
-	MTLSPolicyConflict = diag.NewMessageType(diag.Error, "IST0113", "A DestinationRule and Policy are in conflict with regards to mTLS for host %s. The DestinationRule %q specifies that mTLS must be %t but the Policy object %q specifies %s.")
+	MTLSPolicyConflict = diag.NewMessageType(diag.Error, "IST0113", "A DestinationRule and Policy are in conflict with regards to mTLS for host {{.host}}. The DestinationRule {{quote .destinationRuleName}} specifies that mTLS must be {{.destinationRuleMTLSMode}} but the Policy object {{quote .policyName}} specifies {{.policyMTLSMode}}.",
+		diag.WithName("MTLSPolicyConflict"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A DestinationRule and Policy are in conflict with regards to mTLS."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0113/"),
+		diag.WithArgs("host", "destinationRuleName", "destinationRuleMTLSMode", "policyName", "policyMTLSMode"),
+	)
 
 	// DeploymentAssociatedToMultipleServices defines a diag.MessageType for message "DeploymentAssociatedToMultipleServices".
 	// Description: The resulting pods of a service mesh deployment can't be associated with multiple services using the same port but different protocols.
-	DeploymentAssociatedToMultipleServices = diag.NewMessageType(diag.Warning, "IST0116", "This deployment %s is associated with multiple services using port %d but different protocols: %v")
+	DeploymentAssociatedToMultipleServices = diag.NewMessageType(diag.Warning, "IST0116", "This deployment {{.deployment}} is associated with multiple services using port {{.port}} but different protocols: {{.services}}",
+		diag.WithName("DeploymentAssociatedToMultipleServices"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("The resulting pods of a service mesh deployment can't be associated with multiple services using the same port but different protocols."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0116/"),
+		diag.WithArgs("deployment", "port", "services"),
+	)
 
 	// DeploymentRequiresServiceAssociated defines a diag.MessageType for message "DeploymentRequiresServiceAssociated".
 	// Description: The resulting pods of a service mesh deployment must be associated with at least one service.
-	DeploymentRequiresServiceAssociated = diag.NewMessageType(diag.Warning, "IST0117", "No service associated with this deployment. Service mesh deployments must be associated with a service.")
+	DeploymentRequiresServiceAssociated = diag.NewMessageType(diag.Warning, "IST0117", "No service associated with this deployment. Service mesh deployments must be associated with a service.",
+		diag.WithName("DeploymentRequiresServiceAssociated"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("The resulting pods of a service mesh deployment must be associated with at least one service."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0117/"),
+	)
 
 	// PortNameIsNotUnderNamingConvention defines a diag.MessageType for message "PortNameIsNotUnderNamingConvention".
 	// Description: Port name is not under naming convention. Protocol detection is applied to the port.
-	PortNameIsNotUnderNamingConvention = diag.NewMessageType(diag.Info, "IST0118", "Port name %s (port: %d, targetPort: %s) doesn't follow the naming convention of Istio port.")
+	PortNameIsNotUnderNamingConvention = diag.NewMessageType(diag.Info, "IST0118", "Port name {{.portName}} (port: {{.port}}, targetPort: {{.targetPort}}) doesn't follow the naming convention of Istio port.",
+		diag.WithName("PortNameIsNotUnderNamingConvention"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Port name is not under naming convention. Protocol detection is applied to the port."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0118/"),
+		diag.WithArgs("portName", "port", "targetPort"),
+	)
 
 	// JwtFailureDueToInvalidServicePortPrefix defines a diag.MessageType for message "JwtFailureDueToInvalidServicePortPrefix".
 	// Description: Authentication policy with JWT targets Service with invalid port specification.
-	JwtFailureDueToInvalidServicePortPrefix = diag.NewMessageType(diag.Warning, "IST0119", "Authentication policy with JWT targets Service with invalid port specification (port: %d, name: %s, protocol: %s, targetPort: %s).")
+	JwtFailureDueToInvalidServicePortPrefix = diag.NewMessageType(diag.Warning, "IST0119", "Authentication policy with JWT targets Service with invalid port specification (port: {{.port}}, name: {{.portName}}, protocol: {{.protocol}}, targetPort: {{.targetPort}}).",
+		diag.WithName("JwtFailureDueToInvalidServicePortPrefix"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Authentication policy with JWT targets Service with invalid port specification."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0119/"),
+		diag.WithArgs("port", "portName", "protocol", "targetPort"),
+	)
 
 	// InvalidRegexp defines a diag.MessageType for message "InvalidRegexp".
 	// Description: Invalid Regex
-	InvalidRegexp = diag.NewMessageType(diag.Warning, "IST0122", "Field %q regular expression invalid: %q (%s)")
+	InvalidRegexp = diag.NewMessageType(diag.Warning, "IST0122", "Field {{quote .where}} regular expression invalid: {{quote .re}} ({{.problem}})",
+		diag.WithName("InvalidRegexp"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Invalid Regex"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0122/"),
+		diag.WithArgs("where", "re", "problem"),
+	)
 
 	// NamespaceMultipleInjectionLabels defines a diag.MessageType for message "NamespaceMultipleInjectionLabels".
 	// Description: A namespace has both new and legacy injection labels
-	NamespaceMultipleInjectionLabels = diag.NewMessageType(diag.Warning, "IST0123", "The namespace has both new and legacy injection labels. Run 'kubectl label namespace %s istio.io/rev-' or 'kubectl label namespace %s istio-injection-'")
+	NamespaceMultipleInjectionLabels = diag.NewMessageType(diag.Warning, "IST0123", "The namespace has both new and legacy injection labels. Run 'kubectl label namespace {{.namespace}} istio.io/rev-' or 'kubectl label namespace {{.namespace2}} istio-injection-'",
+		diag.WithName("NamespaceMultipleInjectionLabels"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A namespace has both new and legacy injection labels"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0123/"),
+		diag.WithArgs("namespace", "namespace2"),
+	)
 
 	// InvalidAnnotation defines a diag.MessageType for message "InvalidAnnotation".
 	// Description: An Istio annotation that is not valid
-	InvalidAnnotation = diag.NewMessageType(diag.Warning, "IST0125", "Invalid annotation %s: %s")
+	InvalidAnnotation = diag.NewMessageType(diag.Warning, "IST0125", "Invalid annotation {{.annotation}}: {{.problem}}",
+		diag.WithName("InvalidAnnotation"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("An Istio annotation that is not valid"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0125/"),
+		diag.WithArgs("annotation", "problem"),
+	)
 
 	// UnknownMeshNetworksServiceRegistry defines a diag.MessageType for message "UnknownMeshNetworksServiceRegistry".
 	// Description: A service registry in Mesh Networks is unknown
-	UnknownMeshNetworksServiceRegistry = diag.NewMessageType(diag.Error, "IST0126", "Unknown service registry %s in network %s")
+	UnknownMeshNetworksServiceRegistry = diag.NewMessageType(diag.Error, "IST0126", "Unknown service registry {{.serviceregistry}} in network {{.network}}",
+		diag.WithName("UnknownMeshNetworksServiceRegistry"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A service registry in Mesh Networks is unknown"),
+		diag.WithURL(""),
+		diag.WithArgs("serviceregistry", "network"),
+	)
 
 	// NoMatchingWorkloadsFound defines a diag.MessageType for message "NoMatchingWorkloadsFound".
 	// Description: There aren't workloads matching the resource labels
-	NoMatchingWorkloadsFound = diag.NewMessageType(diag.Warning, "IST0127", "No matching workloads for this resource with the following labels: %s")
+	NoMatchingWorkloadsFound = diag.NewMessageType(diag.Warning, "IST0127", "No matching workloads for this resource with the following labels: {{.labels}}",
+		diag.WithName("NoMatchingWorkloadsFound"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("There aren't workloads matching the resource labels"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0127/"),
+		diag.WithArgs("labels"),
+	)
 
 	// NoServerCertificateVerificationDestinationLevel defines a diag.MessageType for message "NoServerCertificateVerificationDestinationLevel".
 	// Description: No caCertificates are set in DestinationRule, this results in no verification of presented server certificate.
-	NoServerCertificateVerificationDestinationLevel = diag.NewMessageType(diag.Error, "IST0128", "DestinationRule %s in namespace %s has TLS mode set to %s but no caCertificates are set to validate server identity for host: %s")
+	NoServerCertificateVerificationDestinationLevel = diag.NewMessageType(diag.Error, "IST0128", "DestinationRule {{.destinationrule}} in namespace {{.namespace}} has TLS mode set to {{.mode}} but no caCertificates are set to validate server identity for host: {{.host}}",
+		diag.WithName("NoServerCertificateVerificationDestinationLevel"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("No caCertificates are set in DestinationRule, this results in no verification of presented server certificate."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0128/"),
+		diag.WithArgs("destinationrule", "namespace", "mode", "host"),
+	)
 
 	// NoServerCertificateVerificationPortLevel defines a diag.MessageType for message "NoServerCertificateVerificationPortLevel".
 	// Description: No caCertificates are set in DestinationRule, this results in no verification of presented server certificate for traffic to a given port.
-	NoServerCertificateVerificationPortLevel = diag.NewMessageType(diag.Warning, "IST0129", "DestinationRule %s in namespace %s has TLS mode set to %s but no caCertificates are set to validate server identity for host: %s at port %s")
+	NoServerCertificateVerificationPortLevel = diag.NewMessageType(diag.Warning, "IST0129", "DestinationRule {{.destinationrule}} in namespace {{.namespace}} has TLS mode set to {{.mode}} but no caCertificates are set to validate server identity for host: {{.host}} at port {{.port}}",
+		diag.WithName("NoServerCertificateVerificationPortLevel"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("No caCertificates are set in DestinationRule, this results in no verification of presented server certificate for traffic to a given port."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0129/"),
+		diag.WithArgs("destinationrule", "namespace", "mode", "host", "port"),
+	)
 
 	// VirtualServiceUnreachableRule defines a diag.MessageType for message "VirtualServiceUnreachableRule".
 	// Description: A VirtualService rule will never be used because a previous rule uses the same match.
-	VirtualServiceUnreachableRule = diag.NewMessageType(diag.Warning, "IST0130", "VirtualService rule %v not used (%s).")
+	VirtualServiceUnreachableRule = diag.NewMessageType(diag.Warning, "IST0130", "VirtualService rule {{.ruleno}} not used ({{.reason}}).",
+		diag.WithName("VirtualServiceUnreachableRule"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A VirtualService rule will never be used because a previous rule uses the same match."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0130/"),
+		diag.WithArgs("ruleno", "reason"),
+	)
 
 	// VirtualServiceIneffectiveMatch defines a diag.MessageType for message "VirtualServiceIneffectiveMatch".
 	// Description: A VirtualService rule match duplicates a match in a previous rule.
-	VirtualServiceIneffectiveMatch = diag.NewMessageType(diag.Info, "IST0131", "VirtualService rule %v match %v is not used (duplicate/overlapping match in rule %v).")
+	VirtualServiceIneffectiveMatch = diag.NewMessageType(diag.Info, "IST0131", "VirtualService rule {{.ruleno}} match {{.matchno}} is not used (duplicate/overlapping match in rule {{.dupno}}).",
+		diag.WithName("VirtualServiceIneffectiveMatch"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A VirtualService rule match duplicates a match in a previous rule."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0131/"),
+		diag.WithArgs("ruleno", "matchno", "dupno"),
+	)
 
 	// VirtualServiceHostNotFoundInGateway defines a diag.MessageType for message "VirtualServiceHostNotFoundInGateway".
 	// Description: Host defined in VirtualService not found in Gateway.
-	VirtualServiceHostNotFoundInGateway = diag.NewMessageType(diag.Warning, "IST0132", "one or more host %v defined in VirtualService %s not found in Gateway %s.")
+	VirtualServiceHostNotFoundInGateway = diag.NewMessageType(diag.Warning, "IST0132", "one or more host {{.host}} defined in VirtualService {{.virtualservice}} not found in Gateway {{.gateway}}.",
+		diag.WithName("VirtualServiceHostNotFoundInGateway"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Host defined in VirtualService not found in Gateway."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0132/"),
+		diag.WithArgs("host", "virtualservice", "gateway"),
+	)
 
 	// SchemaWarning defines a diag.MessageType for message "SchemaWarning".
 	// Description: The resource has a schema validation warning.
-	SchemaWarning = diag.NewMessageType(diag.Warning, "IST0133", "Schema validation warning: %v")
+	SchemaWarning = diag.NewMessageType(diag.Warning, "IST0133", "Schema validation warning: {{.err}}",
+		diag.WithName("SchemaWarning"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("The resource has a schema validation warning."),
+		diag.WithURL(""),
+		diag.WithArgs("err"),
+	)
 
 	// ServiceEntryAddressesRequired defines a diag.MessageType for message "ServiceEntryAddressesRequired".
 	// Description: Virtual IP addresses are required for ports serving TCP (or unset) protocol
-	ServiceEntryAddressesRequired = diag.NewMessageType(diag.Warning, "IST0134", "ServiceEntry addresses are required for this protocol.")
+	ServiceEntryAddressesRequired = diag.NewMessageType(diag.Warning, "IST0134", "ServiceEntry addresses are required for this protocol.",
+		diag.WithName("ServiceEntryAddressesRequired"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Virtual IP addresses are required for ports serving TCP (or unset) protocol"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0134/"),
+	)
 
 	// DeprecatedAnnotation defines a diag.MessageType for message "DeprecatedAnnotation".
 	// Description: A resource is using a deprecated Istio annotation.
-	DeprecatedAnnotation = diag.NewMessageType(diag.Info, "IST0135", "Annotation %q has been deprecated%s and may not work in future Istio versions.")
+	DeprecatedAnnotation = diag.NewMessageType(diag.Info, "IST0135", "Annotation {{quote .annotation}} has been deprecated{{.extra}} and may not work in future Istio versions.",
+		diag.WithName("DeprecatedAnnotation"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A resource is using a deprecated Istio annotation."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0135/"),
+		diag.WithArgs("annotation", "extra"),
+	)
 
 	// AlphaAnnotation defines a diag.MessageType for message "AlphaAnnotation".
 	// Description: An Istio annotation may not be suitable for production.
-	AlphaAnnotation = diag.NewMessageType(diag.Info, "IST0136", "Annotation %q is part of an alpha-phase feature and may be incompletely supported.")
+	AlphaAnnotation = diag.NewMessageType(diag.Info, "IST0136", "Annotation {{quote .annotation}} is part of an alpha-phase feature and may be incompletely supported.",
+		diag.WithName("AlphaAnnotation"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("An Istio annotation may not be suitable for production."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0136/"),
+		diag.WithArgs("annotation"),
+	)
 
 	// DeploymentConflictingPorts defines a diag.MessageType for message "DeploymentConflictingPorts".
 	// Description: Two services selecting the same workload with the same targetPort MUST refer to the same port.
-	DeploymentConflictingPorts = diag.NewMessageType(diag.Warning, "IST0137", "This deployment %s is associated with multiple services %v using targetPort %q but different ports: %v.")
+	DeploymentConflictingPorts = diag.NewMessageType(diag.Warning, "IST0137", "This deployment {{.deployment}} is associated with multiple services {{.services}} using targetPort {{quote .targetPort}} but different ports: {{.ports}}.",
+		diag.WithName("DeploymentConflictingPorts"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Two services selecting the same workload with the same targetPort MUST refer to the same port."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0137/"),
+		diag.WithArgs("deployment", "services", "targetPort", "ports"),
+	)
 
 	// GatewayDuplicateCertificate defines a diag.MessageType for message "GatewayDuplicateCertificate".
 	// Description: Duplicate certificate in multiple gateways may cause 404s if clients re-use HTTP2 connections.
-	GatewayDuplicateCertificate = diag.NewMessageType(diag.Warning, "IST0138", "Duplicate certificate in multiple gateways %v may cause 404s if clients re-use HTTP2 connections.")
+	GatewayDuplicateCertificate = diag.NewMessageType(diag.Warning, "IST0138", "Duplicate certificate in multiple gateways {{.gateways}} may cause 404s if clients re-use HTTP2 connections.",
+		diag.WithName("GatewayDuplicateCertificate"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Duplicate certificate in multiple gateways may cause 404s if clients re-use HTTP2 connections."),
+		diag.WithURL(""),
+		diag.WithArgs("gateways"),
+	)
 
 	// InvalidWebhook defines a diag.MessageType for message "InvalidWebhook".
 	// Description: Webhook is invalid or references a control plane service that does not exist.
-	InvalidWebhook = diag.NewMessageType(diag.Error, "IST0139", "%v")
+	InvalidWebhook = diag.NewMessageType(diag.Error, "IST0139", "{{.error}}",
+		diag.WithName("InvalidWebhook"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Webhook is invalid or references a control plane service that does not exist."),
+		diag.WithURL(""),
+		diag.WithArgs("error"),
+	)
 
 	// IngressRouteRulesNotAffected defines a diag.MessageType for message "IngressRouteRulesNotAffected".
 	// Description: Route rules have no effect on ingress gateway requests
-	IngressRouteRulesNotAffected = diag.NewMessageType(diag.Warning, "IST0140", "Subset in virtual service %s has no effect on ingress gateway %s requests")
+	IngressRouteRulesNotAffected = diag.NewMessageType(diag.Warning, "IST0140", "Subset in virtual service {{.virtualservicesubset}} has no effect on ingress gateway {{.virtualservice}} requests",
+		diag.WithName("IngressRouteRulesNotAffected"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Route rules have no effect on ingress gateway requests"),
+		diag.WithURL(""),
+		diag.WithArgs("virtualservicesubset", "virtualservice"),
+	)
 
 	// InsufficientPermissions defines a diag.MessageType for message "InsufficientPermissions".
 	// Description: Required permissions to install Istio are missing.
-	InsufficientPermissions = diag.NewMessageType(diag.Error, "IST0141", "Missing required permission to create resource %v (%v)")
+	InsufficientPermissions = diag.NewMessageType(diag.Error, "IST0141", "Missing required permission to create resource {{.resource}} ({{.error}})",
+		diag.WithName("InsufficientPermissions"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Required permissions to install Istio are missing."),
+		diag.WithURL(""),
+		diag.WithArgs("resource", "error"),
+	)
 
 	// UnsupportedKubernetesVersion defines a diag.MessageType for message "UnsupportedKubernetesVersion".
 	// Description: The Kubernetes version is not supported
-	UnsupportedKubernetesVersion = diag.NewMessageType(diag.Error, "IST0142", "The Kubernetes Version %q is lower than the minimum version: %v")
+	UnsupportedKubernetesVersion = diag.NewMessageType(diag.Error, "IST0142", "The Kubernetes Version {{quote .version}} is lower than the minimum version: {{.minimumVersion}}",
+		diag.WithName("UnsupportedKubernetesVersion"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("The Kubernetes version is not supported"),
+		diag.WithURL(""),
+		diag.WithArgs("version", "minimumVersion"),
+	)
 
 	// LocalhostListener defines a diag.MessageType for message "LocalhostListener".
 	// Description: A port exposed in a Service is bound to a localhost address
-	LocalhostListener = diag.NewMessageType(diag.Error, "IST0143", "Port %v is exposed in a Service but listens on localhost. It will not be exposed to other pods.")
+	LocalhostListener = diag.NewMessageType(diag.Error, "IST0143", "Port {{.port}} is exposed in a Service but listens on localhost. It will not be exposed to other pods.",
+		diag.WithName("LocalhostListener"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("A port exposed in a Service is bound to a localhost address"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0143/"),
+		diag.WithArgs("port"),
+	)
 
 	// InvalidApplicationUID defines a diag.MessageType for message "InvalidApplicationUID".
 	// Description: Application pods should not run as user ID (UID) 1337
-	InvalidApplicationUID = diag.NewMessageType(diag.Warning, "IST0144", "User ID (UID) 1337 is reserved for the sidecar proxy.")
+	InvalidApplicationUID = diag.NewMessageType(diag.Warning, "IST0144", "User ID (UID) 1337 is reserved for the sidecar proxy.",
+		diag.WithName("InvalidApplicationUID"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Application pods should not run as user ID (UID) 1337"),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0144/"),
+	)
 
 	// ConflictingGateways defines a diag.MessageType for message "ConflictingGateways".
 	// Description: Gateway should not have the same selector, port and matched hosts of server
-	ConflictingGateways = diag.NewMessageType(diag.Error, "IST0145", "Conflict with gateways %s (workload selector %s, port %s, hosts %v).")
+	ConflictingGateways = diag.NewMessageType(diag.Error, "IST0145", "Conflict with gateways {{.gateway}} (workload selector {{.selector}}, port {{.portnumber}}, hosts {{.hosts}}).",
+		diag.WithName("ConflictingGateways"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Gateway should not have the same selector, port and matched hosts of server"),
+		diag.WithURL(""),
+		diag.WithArgs("gateway", "selector", "portnumber", "hosts"),
+	)
 
 	// ImageAutoWithoutInjectionWarning defines a diag.MessageType for message "ImageAutoWithoutInjectionWarning".
 	// Description: Deployments with `image: auto` should be targeted for injection.
-	ImageAutoWithoutInjectionWarning = diag.NewMessageType(diag.Warning, "IST0146", "%s %s contains `image: auto` but does not match any Istio injection webhook selectors.")
+	ImageAutoWithoutInjectionWarning = diag.NewMessageType(diag.Warning, "IST0146", "{{.resourceType}} {{.resourceName}} contains `image: auto` but does not match any Istio injection webhook selectors.",
+		diag.WithName("ImageAutoWithoutInjectionWarning"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Deployments with `image: auto` should be targeted for injection."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0146/"),
+		diag.WithArgs("resourceType", "resourceName"),
+	)
 
 	// ImageAutoWithoutInjectionError defines a diag.MessageType for message "ImageAutoWithoutInjectionError".
 	// Description: Pods with `image: auto` should be targeted for injection.
-	ImageAutoWithoutInjectionError = diag.NewMessageType(diag.Error, "IST0147", "%s %s contains `image: auto` but does not match any Istio injection webhook selectors.")
+	ImageAutoWithoutInjectionError = diag.NewMessageType(diag.Error, "IST0147", "{{.resourceType}} {{.resourceName}} contains `image: auto` but does not match any Istio injection webhook selectors.",
+		diag.WithName("ImageAutoWithoutInjectionError"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("Pods with `image: auto` should be targeted for injection."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0147/"),
+		diag.WithArgs("resourceType", "resourceName"),
+	)
 
 	// NamespaceInjectionEnabledByDefault defines a diag.MessageType for message "NamespaceInjectionEnabledByDefault".
 	// Description: user namespace should be injectable if Istio is installed with enableNamespacesByDefault enabled and neither injection label is set.
-	NamespaceInjectionEnabledByDefault = diag.NewMessageType(diag.Info, "IST0148", "is enabled for Istio injection, as Istio is installed with enableNamespacesByDefault as true.")
+	NamespaceInjectionEnabledByDefault = diag.NewMessageType(diag.Info, "IST0148", "is enabled for Istio injection, as Istio is installed with enableNamespacesByDefault as true.",
+		diag.WithName("NamespaceInjectionEnabledByDefault"),
+		diag.WithCategory(string(CategoryAnalysis)),
+		diag.WithDescription("user namespace should be injectable if Istio is installed with enableNamespacesByDefault enabled and neither injection label is set."),
+		diag.WithURL("https://istio.io/latest/docs/reference/config/analysis/ist0148/"),
+	)
+)
+
+// Category is a category of message types, as declared in messages.yaml.
+type Category string
+
+const (
+	// CategoryInternal is the category of messages with codes IST0001 to IST0100.
+	CategoryInternal Category = "Internal"
+	// CategoryAnalysis is the category of messages with codes IST0101 to IST9999.
+	CategoryAnalysis Category = "Analysis"
 )
 
+// Categories returns all message categories, in the order they are declared.
+func Categories() []Category {
+	return []Category{
+		CategoryInternal,
+		CategoryAnalysis,
+	}
+}
+
+// CategoryOf returns the category of a message type, which is empty for message types not generated from
+// messages.yaml that don't declare one.
+func CategoryOf(mt *diag.MessageType) Category {
+	return Category(mt.Category())
+}
+
 // All returns a list of all known message types.
 func All() []*diag.MessageType {
 	return []*diag.MessageType{
@@ -241,6 +533,180 @@ func All() []*diag.MessageType {
 	}
 }
 
+func init() {
+	for _, mt := range All() {
+		if err := diag.RegisterMessageType(mt); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ForCode returns the message type with the given code, or nil if there is none. This includes message types
+// registered at runtime with diag.RegisterMessageType, as well as those listed by All.
+func ForCode(code string) *diag.MessageType {
+	mt, _ := diag.LookupMessageType(code)
+	return mt
+}
+
+// Constructors returns the arguments of each generated message constructor, in order and keyed by constructor name.
+// The leading *resource.Instance argument shared by every constructor is omitted. It is intended for tooling that
+// checks calls to the constructors.
+func Constructors() map[string][]ArgInfo {
+	return map[string][]ArgInfo{
+		"NewInternalError":                                   {{Name: "detail", Type: "string"}},
+		"NewDeprecated":                                      {{Name: "detail", Type: "string"}},
+		"NewReferencedResourceNotFound":                      {{Name: "reftype", Type: "string"}, {Name: "refval", Type: "string"}},
+		"NewNamespaceNotInjected":                            {{Name: "namespace", Type: "string"}, {Name: "namespace2", Type: "string"}},
+		"NewPodMissingProxy":                                 {},
+		"NewGatewayPortNotOnWorkload":                        {{Name: "selector", Type: "string"}, {Name: "port", Type: "int"}},
+		"NewIstioProxyImageMismatch":                         {{Name: "proxyImage", Type: "string"}, {Name: "injectionImage", Type: "string"}},
+		"NewSchemaValidationError":                           {{Name: "err", Type: "error"}},
+		"NewMisplacedAnnotation":                             {{Name: "annotation", Type: "string"}, {Name: "kind", Type: "string"}},
+		"NewUnknownAnnotation":                               {{Name: "annotation", Type: "string"}},
+		"NewConflictingMeshGatewayVirtualServiceHosts":       {{Name: "virtualServices", Type: "string"}, {Name: "host", Type: "string"}},
+		"NewConflictingSidecarWorkloadSelectors":             {{Name: "conflictingSidecars", Type: "[]string"}, {Name: "namespace", Type: "string"}, {Name: "workloadPod", Type: "string"}},
+		"NewMultipleSidecarsWithoutWorkloadSelectors":        {{Name: "conflictingSidecars", Type: "[]string"}, {Name: "namespace", Type: "string"}},
+		"NewVirtualServiceDestinationPortSelectorRequired":   {{Name: "destHost", Type: "string"}, {Name: "destPorts", Type: "[]int"}},
+		"NewMTLSPolicyConflict":                              {{Name: "host", Type: "string"}, {Name: "destinationRuleName", Type: "string"}, {Name: "destinationRuleMTLSMode", Type: "bool"}, {Name: "policyName", Type: "string"}, {Name: "policyMTLSMode", Type: "string"}},
+		"NewDeploymentAssociatedToMultipleServices":          {{Name: "deployment", Type: "string"}, {Name: "port", Type: "int32"}, {Name: "services", Type: "[]string"}},
+		"NewDeploymentRequiresServiceAssociated":             {},
+		"NewPortNameIsNotUnderNamingConvention":              {{Name: "portName", Type: "string"}, {Name: "port", Type: "int"}, {Name: "targetPort", Type: "string"}},
+		"NewJwtFailureDueToInvalidServicePortPrefix":         {{Name: "port", Type: "int"}, {Name: "portName", Type: "string"}, {Name: "protocol", Type: "string"}, {Name: "targetPort", Type: "string"}},
+		"NewInvalidRegexp":                                   {{Name: "where", Type: "string"}, {Name: "re", Type: "string"}, {Name: "problem", Type: "string"}},
+		"NewNamespaceMultipleInjectionLabels":                {{Name: "namespace", Type: "string"}, {Name: "namespace2", Type: "string"}},
+		"NewInvalidAnnotation":                               {{Name: "annotation", Type: "string"}, {Name: "problem", Type: "string"}},
+		"NewUnknownMeshNetworksServiceRegistry":              {{Name: "serviceregistry", Type: "string"}, {Name: "network", Type: "string"}},
+		"NewNoMatchingWorkloadsFound":                        {{Name: "labels", Type: "string"}},
+		"NewNoServerCertificateVerificationDestinationLevel": {{Name: "destinationrule", Type: "string"}, {Name: "namespace", Type: "string"}, {Name: "mode", Type: "string"}, {Name: "host", Type: "string"}},
+		"NewNoServerCertificateVerificationPortLevel":        {{Name: "destinationrule", Type: "string"}, {Name: "namespace", Type: "string"}, {Name: "mode", Type: "string"}, {Name: "host", Type: "string"}, {Name: "port", Type: "string"}},
+		"NewVirtualServiceUnreachableRule":                   {{Name: "ruleno", Type: "string"}, {Name: "reason", Type: "string"}},
+		"NewVirtualServiceIneffectiveMatch":                  {{Name: "ruleno", Type: "string"}, {Name: "matchno", Type: "string"}, {Name: "dupno", Type: "string"}},
+		"NewVirtualServiceHostNotFoundInGateway":             {{Name: "host", Type: "[]string"}, {Name: "virtualservice", Type: "string"}, {Name: "gateway", Type: "string"}},
+		"NewSchemaWarning":                                   {{Name: "err", Type: "error"}},
+		"NewServiceEntryAddressesRequired":                   {},
+		"NewDeprecatedAnnotation":                            {{Name: "annotation", Type: "string"}, {Name: "extra", Type: "string"}},
+		"NewAlphaAnnotation":                                 {{Name: "annotation", Type: "string"}},
+		"NewDeploymentConflictingPorts":                      {{Name: "deployment", Type: "string"}, {Name: "services", Type: "[]string"}, {Name: "targetPort", Type: "string"}, {Name: "ports", Type: "[]int32"}},
+		"NewGatewayDuplicateCertificate":                     {{Name: "gateways", Type: "[]string"}},
+		"NewInvalidWebhook":                                  {{Name: "error", Type: "string"}},
+		"NewIngressRouteRulesNotAffected":                    {{Name: "virtualservicesubset", Type: "string"}, {Name: "virtualservice", Type: "string"}},
+		"NewInsufficientPermissions":                         {{Name: "resource", Type: "string"}, {Name: "error", Type: "string"}},
+		"NewUnsupportedKubernetesVersion":                    {{Name: "version", Type: "string"}, {Name: "minimumVersion", Type: "string"}},
+		"NewLocalhostListener":                               {{Name: "port", Type: "string"}},
+		"NewInvalidApplicationUID":                           {},
+		"NewConflictingGateways":                             {{Name: "gateway", Type: "string"}, {Name: "selector", Type: "string"}, {Name: "portnumber", Type: "string"}, {Name: "hosts", Type: "string"}},
+		"NewImageAutoWithoutInjectionWarning":                {{Name: "resourceType", Type: "string"}, {Name: "resourceName", Type: "string"}},
+		"NewImageAutoWithoutInjectionError":                  {{Name: "resourceType", Type: "string"}, {Name: "resourceName", Type: "string"}},
+		"NewNamespaceInjectionEnabledByDefault":              {},
+	}
+}
+
+// MetricDescriptors returns a descriptor of a metric counting the messages of each type listed by All, in the same
+// order, so that metrics exporters can register them.
+func MetricDescriptors() []MetricDescriptor {
+	return []MetricDescriptor{
+		{Name: "istio_analysis_ist0001_total", Help: "There was an internal error in the toolchain. This is almost always a bug in the implementation.", Code: "IST0001"},
+		{Name: "istio_analysis_ist0002_total", Help: "A feature that the configuration is depending on is now deprecated.", Code: "IST0002"},
+		{Name: "istio_analysis_ist0101_total", Help: "A resource being referenced does not exist.", Code: "IST0101"},
+		{Name: "istio_analysis_ist0102_total", Help: "A namespace is not enabled for Istio injection.", Code: "IST0102"},
+		{Name: "istio_analysis_ist0103_total", Help: "A pod is missing the Istio proxy.", Code: "IST0103"},
+		{Name: "istio_analysis_ist0104_total", Help: "Unhandled gateway port", Code: "IST0104"},
+		{Name: "istio_analysis_ist0105_total", Help: "The image of the Istio proxy running on the pod does not match the image defined in the injection configuration.", Code: "IST0105"},
+		{Name: "istio_analysis_ist0106_total", Help: "The resource has a schema validation error.", Code: "IST0106"},
+		{Name: "istio_analysis_ist0107_total", Help: "An Istio annotation is applied to the wrong kind of resource.", Code: "IST0107"},
+		{Name: "istio_analysis_ist0108_total", Help: "An Istio annotation is not recognized for any kind of resource", Code: "IST0108"},
+		{Name: "istio_analysis_ist0109_total", Help: "Conflicting hosts on VirtualServices associated with mesh gateway", Code: "IST0109"},
+		{Name: "istio_analysis_ist0110_total", Help: "A Sidecar resource selects the same workloads as another Sidecar resource", Code: "IST0110"},
+		{Name: "istio_analysis_ist0111_total", Help: "More than one sidecar resource in a namespace has no workload selector", Code: "IST0111"},
+		{Name: "istio_analysis_ist0112_total", Help: "A VirtualService routes to a service with more than one port exposed, but does not specify which to use.", Code: "IST0112"},
+		{Name: "istio_analysis_ist0113_total", Help: "A DestinationRule and Policy are in conflict with regards to mTLS.", Code: "IST0113"},
+		{Name: "istio_analysis_ist0116_total", Help: "The resulting pods of a service mesh deployment can't be associated with multiple services using the same port but different protocols.", Code: "IST0116"},
+		{Name: "istio_analysis_ist0117_total", Help: "The resulting pods of a service mesh deployment must be associated with at least one service.", Code: "IST0117"},
+		{Name: "istio_analysis_ist0118_total", Help: "Port name is not under naming convention. Protocol detection is applied to the port.", Code: "IST0118"},
+		{Name: "istio_analysis_ist0119_total", Help: "Authentication policy with JWT targets Service with invalid port specification.", Code: "IST0119"},
+		{Name: "istio_analysis_ist0122_total", Help: "Invalid Regex", Code: "IST0122"},
+		{Name: "istio_analysis_ist0123_total", Help: "A namespace has both new and legacy injection labels", Code: "IST0123"},
+		{Name: "istio_analysis_ist0125_total", Help: "An Istio annotation that is not valid", Code: "IST0125"},
+		{Name: "istio_analysis_ist0126_total", Help: "A service registry in Mesh Networks is unknown", Code: "IST0126"},
+		{Name: "istio_analysis_ist0127_total", Help: "There aren't workloads matching the resource labels", Code: "IST0127"},
+		{Name: "istio_analysis_ist0128_total", Help: "No caCertificates are set in DestinationRule, this results in no verification of presented server certificate.", Code: "IST0128"},
+		{Name: "istio_analysis_ist0129_total", Help: "No caCertificates are set in DestinationRule, this results in no verification of presented server certificate for traffic to a given port.", Code: "IST0129"},
+		{Name: "istio_analysis_ist0130_total", Help: "A VirtualService rule will never be used because a previous rule uses the same match.", Code: "IST0130"},
+		{Name: "istio_analysis_ist0131_total", Help: "A VirtualService rule match duplicates a match in a previous rule.", Code: "IST0131"},
+		{Name: "istio_analysis_ist0132_total", Help: "Host defined in VirtualService not found in Gateway.", Code: "IST0132"},
+		{Name: "istio_analysis_ist0133_total", Help: "The resource has a schema validation warning.", Code: "IST0133"},
+		{Name: "istio_analysis_ist0134_total", Help: "Virtual IP addresses are required for ports serving TCP (or unset) protocol", Code: "IST0134"},
+		{Name: "istio_analysis_ist0135_total", Help: "A resource is using a deprecated Istio annotation.", Code: "IST0135"},
+		{Name: "istio_analysis_ist0136_total", Help: "An Istio annotation may not be suitable for production.", Code: "IST0136"},
+		{Name: "istio_analysis_ist0137_total", Help: "Two services selecting the same workload with the same targetPort MUST refer to the same port.", Code: "IST0137"},
+		{Name: "istio_analysis_ist0138_total", Help: "Duplicate certificate in multiple gateways may cause 404s if clients re-use HTTP2 connections.", Code: "IST0138"},
+		{Name: "istio_analysis_ist0139_total", Help: "Webhook is invalid or references a control plane service that does not exist.", Code: "IST0139"},
+		{Name: "istio_analysis_ist0140_total", Help: "Route rules have no effect on ingress gateway requests", Code: "IST0140"},
+		{Name: "istio_analysis_ist0141_total", Help: "Required permissions to install Istio are missing.", Code: "IST0141"},
+		{Name: "istio_analysis_ist0142_total", Help: "The Kubernetes version is not supported", Code: "IST0142"},
+		{Name: "istio_analysis_ist0143_total", Help: "A port exposed in a Service is bound to a localhost address", Code: "IST0143"},
+		{Name: "istio_analysis_ist0144_total", Help: "Application pods should not run as user ID (UID) 1337", Code: "IST0144"},
+		{Name: "istio_analysis_ist0145_total", Help: "Gateway should not have the same selector, port and matched hosts of server", Code: "IST0145"},
+		{Name: "istio_analysis_ist0146_total", Help: "Deployments with `image: auto` should be targeted for injection.", Code: "IST0146"},
+		{Name: "istio_analysis_ist0147_total", Help: "Pods with `image: auto` should be targeted for injection.", Code: "IST0147"},
+		{Name: "istio_analysis_ist0148_total", Help: "user namespace should be injectable if Istio is installed with enableNamespacesByDefault enabled and neither injection label is set.", Code: "IST0148"},
+	}
+}
+
+// SampleMessages returns one instance of each known message type, with deterministic placeholder values filled in for
+// the arguments: "sample-string" for strings, zero for numbers, false for bools, an error with the text "sample-error"
+// for errors, a single placeholder element for slices and the zero value for any other type. It is intended for use as
+// a fixture when testing formatters, as the rendered messages are the same on every call.
+func SampleMessages() diag.Messages {
+	return diag.Messages{
+		NewInternalError(nil, "sample-string"),
+		NewDeprecated(nil, "sample-string"),
+		NewReferencedResourceNotFound(nil, "sample-string", "sample-string"),
+		NewNamespaceNotInjected(nil, "sample-string", "sample-string"),
+		NewPodMissingProxy(nil),
+		NewGatewayPortNotOnWorkload(nil, "sample-string", 0),
+		NewIstioProxyImageMismatch(nil, "sample-string", "sample-string"),
+		NewSchemaValidationError(nil, errors.New("sample-error")),
+		NewMisplacedAnnotation(nil, "sample-string", "sample-string"),
+		NewUnknownAnnotation(nil, "sample-string"),
+		NewConflictingMeshGatewayVirtualServiceHosts(nil, "sample-string", "sample-string"),
+		NewConflictingSidecarWorkloadSelectors(nil, []string{"sample-string"}, "sample-string", "sample-string"),
+		NewMultipleSidecarsWithoutWorkloadSelectors(nil, []string{"sample-string"}, "sample-string"),
+		NewVirtualServiceDestinationPortSelectorRequired(nil, "sample-string", []int{0}),
+		NewMTLSPolicyConflict(nil, "sample-string", "sample-string", false, "sample-string", "sample-string"),
+		NewDeploymentAssociatedToMultipleServices(nil, "sample-string", 0, []string{"sample-string"}),
+		NewDeploymentRequiresServiceAssociated(nil),
+		NewPortNameIsNotUnderNamingConvention(nil, "sample-string", 0, "sample-string"),
+		NewJwtFailureDueToInvalidServicePortPrefix(nil, 0, "sample-string", "sample-string", "sample-string"),
+		NewInvalidRegexp(nil, "sample-string", "sample-string", "sample-string"),
+		NewNamespaceMultipleInjectionLabels(nil, "sample-string", "sample-string"),
+		NewInvalidAnnotation(nil, "sample-string", "sample-string"),
+		NewUnknownMeshNetworksServiceRegistry(nil, "sample-string", "sample-string"),
+		NewNoMatchingWorkloadsFound(nil, "sample-string"),
+		NewNoServerCertificateVerificationDestinationLevel(nil, "sample-string", "sample-string", "sample-string", "sample-string"),
+		NewNoServerCertificateVerificationPortLevel(nil, "sample-string", "sample-string", "sample-string", "sample-string", "sample-string"),
+		NewVirtualServiceUnreachableRule(nil, "sample-string", "sample-string"),
+		NewVirtualServiceIneffectiveMatch(nil, "sample-string", "sample-string", "sample-string"),
+		NewVirtualServiceHostNotFoundInGateway(nil, []string{"sample-string"}, "sample-string", "sample-string"),
+		NewSchemaWarning(nil, errors.New("sample-error")),
+		NewServiceEntryAddressesRequired(nil),
+		NewDeprecatedAnnotation(nil, "sample-string", "sample-string"),
+		NewAlphaAnnotation(nil, "sample-string"),
+		NewDeploymentConflictingPorts(nil, "sample-string", []string{"sample-string"}, "sample-string", []int32{0}),
+		NewGatewayDuplicateCertificate(nil, []string{"sample-string"}),
+		NewInvalidWebhook(nil, "sample-string"),
+		NewIngressRouteRulesNotAffected(nil, "sample-string", "sample-string"),
+		NewInsufficientPermissions(nil, "sample-string", "sample-string"),
+		NewUnsupportedKubernetesVersion(nil, "sample-string", "sample-string"),
+		NewLocalhostListener(nil, "sample-string"),
+		NewInvalidApplicationUID(nil),
+		NewConflictingGateways(nil, "sample-string", "sample-string", "sample-string", "sample-string"),
+		NewImageAutoWithoutInjectionWarning(nil, "sample-string", "sample-string"),
+		NewImageAutoWithoutInjectionError(nil, "sample-string", "sample-string"),
+		NewNamespaceInjectionEnabledByDefault(nil),
+	}
+}
+
 // NewInternalError returns a new diag.Message based on InternalError.
 func NewInternalError(r *resource.Instance, detail string) diag.Message {
 	return diag.NewMessage(
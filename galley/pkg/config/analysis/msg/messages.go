@@ -15,6 +15,27 @@
 package msg
 
 // Create static initializers file
-//go:generate go run "$REPO_ROOT/galley/pkg/config/analysis/msg/generate.main.go" messages.yaml messages.gen.go
+//go:generate go run "$REPO_ROOT/galley/pkg/config/analysis/msg/generate.main.go" -json-output messages.json -translations-output messages.pot -metric-descriptors -examples-output examples.gen_test.go -code-map-output code_map.txt messages.yaml messages.gen.go
 
 //go:generate goimports -w -local istio.io "$REPO_ROOT/galley/pkg/config/analysis/msg/messages.gen.go"
+
+// ArgInfo describes an argument of a generated message constructor.
+type ArgInfo struct {
+	// Name is the name of the argument, as declared in messages.yaml
+	Name string
+
+	// Type is the Go type of the argument, e.g. "string" or "[]int"
+	Type string
+}
+
+// MetricDescriptor describes a metric counting the messages of a type, for registration by metrics exporters.
+type MetricDescriptor struct {
+	// Name is the name of the metric, derived from the message code, e.g. "istio_analysis_ist0101_total"
+	Name string
+
+	// Help is the help text of the metric, which is the description of the message type
+	Help string
+
+	// Code is the code of the message type counted
+	Code string
+}
@@ -0,0 +1,146 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+)
+
+func TestSampleMessages(t *testing.T) {
+	g := NewWithT(t)
+
+	samples := SampleMessages()
+	all := All()
+
+	g.Expect(samples).To(HaveLen(len(all)))
+	for i, m := range samples {
+		g.Expect(m.Type).To(BeIdenticalTo(all[i]))
+	}
+
+	// Placeholder values must be stable across calls, so rendered output is usable in golden files.
+	again := SampleMessages()
+	for i := range samples {
+		g.Expect(again[i].String()).To(Equal(samples[i].String()))
+	}
+	internal := samples[0]
+	g.Expect(internal.Text()).To(ContainSubstring("sample-string"))
+}
+
+// customMessageType is registered by TestForCode. It is shared between runs of the test, as registering the same
+// message type again has no effect, whereas a new one with the same code would be rejected.
+var customMessageType = diag.NewMessageType(diag.Warning, "EXT0001", "Custom: {{.detail}}", diag.WithArgs("detail"))
+
+func TestForCode(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ForCode("IST0101")).To(BeIdenticalTo(ReferencedResourceNotFound))
+	g.Expect(ForCode("IST9999")).To(BeNil())
+
+	// Out-of-tree message types are found once registered, but may not reuse a known code
+	g.Expect(diag.RegisterMessageType(customMessageType)).To(Succeed())
+	g.Expect(ForCode("EXT0001")).To(BeIdenticalTo(customMessageType))
+	g.Expect(diag.RegisterMessageType(diag.NewMessageType(diag.Error, "IST0101", "Clash"))).NotTo(Succeed())
+}
+
+func TestConstructors(t *testing.T) {
+	g := NewWithT(t)
+
+	constructors := Constructors()
+	g.Expect(constructors).To(HaveLen(len(All())))
+	for _, mt := range All() {
+		args, ok := constructors["New"+mt.Name()]
+		g.Expect(ok).To(BeTrue(), mt.Name())
+
+		var names []string
+		for _, a := range args {
+			names = append(names, a.Name)
+		}
+		g.Expect(names).To(Equal(mt.Args()), mt.Name())
+	}
+
+	g.Expect(constructors["NewGatewayPortNotOnWorkload"]).To(Equal([]ArgInfo{
+		{Name: "selector", Type: "string"},
+		{Name: "port", Type: "int"},
+	}))
+}
+
+func TestMetricDescriptors(t *testing.T) {
+	g := NewWithT(t)
+
+	descriptors := MetricDescriptors()
+	g.Expect(descriptors).To(HaveLen(len(All())))
+	for i, mt := range All() {
+		g.Expect(descriptors[i].Code).To(Equal(mt.Code()))
+		g.Expect(descriptors[i].Help).To(Equal(mt.Description()))
+	}
+	g.Expect(descriptors[2]).To(Equal(MetricDescriptor{
+		Name: "istio_analysis_ist0101_total",
+		Help: "A resource being referenced does not exist.",
+		Code: "IST0101",
+	}))
+}
+
+func TestCategories(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(Categories()).To(Equal([]Category{CategoryInternal, CategoryAnalysis}))
+	g.Expect(CategoryOf(InternalError)).To(Equal(CategoryInternal))
+	g.Expect(CategoryOf(ReferencedResourceNotFound)).To(Equal(CategoryAnalysis))
+	g.Expect(CategoryOf(diag.NewMessageType(diag.Error, "EXT0002", "Custom"))).To(BeEmpty())
+
+	for _, mt := range All() {
+		g.Expect(Categories()).To(ContainElement(CategoryOf(mt)), mt.Name())
+	}
+}
+
+func TestExamplesFor(t *testing.T) {
+	g := NewWithT(t)
+
+	examples := ExamplesFor(UnknownAnnotation.Code())
+	g.Expect(examples).To(HaveLen(1))
+	g.Expect(examples[0].Bad).To(ContainSubstring("networking.istio.io/exportToo"))
+	g.Expect(examples[0].Good).To(ContainSubstring("networking.istio.io/exportTo:"))
+
+	g.Expect(ExamplesFor(InternalError.Code())).To(BeNil())
+	g.Expect(ExamplesFor("IST9999")).To(BeNil())
+}
+
+func TestCodeMap(t *testing.T) {
+	g := NewWithT(t)
+
+	// The committed code map must be regenerated along with the messages
+	b, err := os.ReadFile("code_map.txt")
+	g.Expect(err).To(BeNil())
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+
+	var expected []string
+	for _, mt := range All() {
+		expected = append(expected, mt.Code()+" "+mt.Name())
+	}
+	sort.Strings(expected)
+	g.Expect(lines).To(Equal(expected))
+}
@@ -91,7 +91,16 @@ func (a *analyzerMock) Analyze(c analysis.Context) {
 	a.m.Lock()
 	defer a.m.Unlock()
 
-	ctx := *c.(*context)
+	// The combined analyzer wraps the context to attribute messages to the analyzers reporting them
+	inner := c
+	for {
+		w, ok := inner.(interface{ Unwrap() analysis.Context })
+		if !ok {
+			break
+		}
+		inner = w.Unwrap()
+	}
+	ctx := *inner.(*context)
 
 	c.Exists(a.collectionToAccess, resource.NewFullName("", ""))
 
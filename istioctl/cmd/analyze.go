@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -42,6 +43,7 @@ import (
 	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/url"
+	"istio.io/pkg/version"
 )
 
 // AnalyzerFoundIssuesError indicates that at least one analyzer found problems.
@@ -65,10 +67,21 @@ func (f FileParseError) Error() string {
 
 var (
 	listAnalyzers     bool
+	listMessages      bool
+	messageCategory   string
 	useKube           bool
 	failureThreshold  = formatting.MessageThreshold{diag.Error} // messages at least this level will generate an error exit code
 	outputThreshold   = formatting.MessageThreshold{diag.Info}  // messages at least this level will be included in the output
 	colorize          bool
+	hyperlinks        bool
+	maxMessageLength  int
+	numbered          bool
+	requireOrigin     bool
+	omitOrigin        bool
+	kubectlCommands   bool
+	showExamples      bool
+	runMetadata       bool
+	asciiOutput       bool
 	msgOutputFormat   string
 	meshCfgFile       string
 	selectedNamespace string
@@ -105,21 +118,38 @@ func Analyze() *cobra.Command {
   istioctl analyze -S "IST0103=Pod *.testing" -S "IST0107=Deployment foobar.default"
 
   # List available analyzers
-  istioctl analyze -L`,
+  istioctl analyze -L
+
+  # List the warning and error messages analyzers can report, as JSON
+  istioctl analyze --list-messages --output-threshold Warning -o json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// An explicit --output takes precedence over the default from the environment
+			if !cmd.Flags().Changed("output") {
+				format, err := formatting.DefaultOutputFormat()
+				if err != nil {
+					return CommandParseError{err}
+				}
+				msgOutputFormat = format
+			}
 			msgOutputFormat = strings.ToLower(msgOutputFormat)
-			_, ok := formatting.MsgOutputFormats[msgOutputFormat]
-			if !ok {
+			if !formatting.IsOutputFormat(msgOutputFormat) {
 				return CommandParseError{
 					fmt.Errorf("%s not a valid option for format. See istioctl analyze --help", msgOutputFormat),
 				}
 			}
 
 			if listAnalyzers {
+				if msgOutputFormat == formatting.JSONFormat {
+					return printRulesManifest(cmd)
+				}
 				fmt.Print(AnalyzersAsString(analyzers.All()))
 				return nil
 			}
 
+			if listMessages {
+				return printMessageCatalog(cmd)
+			}
+
 			readers, err := gatherFiles(cmd, args)
 			if err != nil {
 				return err
@@ -240,8 +270,28 @@ func Analyze() *cobra.Command {
 			// Get messages for output
 			outputMessages := result.Messages.SetDocRef("istioctl-analyze").FilterOutLowerThan(outputThreshold.Level)
 
+			var metadata *diag.RunMetadata
+			if runMetadata {
+				metadata = analysisRunMetadata()
+			}
+
 			// Print all the messages to stdout in the specified format
-			output, err := formatting.Print(outputMessages, msgOutputFormat, colorize)
+			output, err := formatting.PrintWithOptions(outputMessages, msgOutputFormat,
+				formatting.RenderOptions{
+					Colorize: colorize,
+					Verbose:  verbose,
+					// Hyperlinks are only emitted where color would be, as both rely on terminal escape sequences
+					Hyperlinks:       hyperlinks && formatting.IstioctlColorDefault(cmd.OutOrStdout()),
+					MaxMessageLength: maxMessageLength,
+					Numbered:         numbered,
+					RequireOrigin:    requireOrigin,
+					OmitOrigin:       omitOrigin,
+					KubectlCommands:  kubectlCommands,
+					Examples:         showExamples,
+					Metadata:         metadata,
+					ASCII:            asciiOutput,
+					Source:           os.ReadFile,
+				})
 			if err != nil {
 				return err
 			}
@@ -287,19 +337,43 @@ func Analyze() *cobra.Command {
 	}
 
 	analysisCmd.PersistentFlags().BoolVarP(&listAnalyzers, "list-analyzers", "L", false,
-		"List the analyzers available to run. Suppresses normal execution.")
+		"List the analyzers available to run. With '-o json', lists them as JSON along with the messages each can report. "+
+			"Suppresses normal execution.")
+	analysisCmd.PersistentFlags().BoolVar(&listMessages, "list-messages", false,
+		"List the messages analyzers can report, honoring --output-threshold and --message-category. "+
+			"Output is a table, or JSON or YAML with '-o json' or '-o yaml'. Suppresses normal execution.")
+	analysisCmd.PersistentFlags().StringVar(&messageCategory, "message-category", "",
+		"Only list messages in this category. Used with --list-messages.")
 	analysisCmd.PersistentFlags().BoolVarP(&useKube, "use-kube", "k", true,
 		"Use live Kubernetes cluster for analysis. Set --use-kube=false to analyze files only.")
 	analysisCmd.PersistentFlags().BoolVar(&colorize, "color", formatting.IstioctlColorDefault(analysisCmd.OutOrStdout()),
 		"Default true.  Disable with '=false' or set $TERM to dumb")
 	analysisCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false,
 		"Enable verbose output")
+	analysisCmd.PersistentFlags().BoolVar(&hyperlinks, "hyperlinks", false,
+		"Render message codes as terminal hyperlinks to their documentation. Ignored when not writing to a terminal.")
+	analysisCmd.PersistentFlags().BoolVar(&numbered, "numbered", false,
+		"Sort messages and number each one, so that it can be referred to. Applies to log, json and yaml output.")
+	analysisCmd.PersistentFlags().BoolVar(&requireOrigin, "require-origin", false,
+		"Fail if any message is not attributed to a resource, which indicates an analyzer bug. For analyzer developers.")
+	analysisCmd.PersistentFlags().BoolVar(&omitOrigin, "omit-origin", false,
+		"Leave the resource each message is on out of log output, printing only its level, code and text.")
+	analysisCmd.PersistentFlags().BoolVar(&kubectlCommands, "kubectl-commands", false,
+		"With --verbose, show a kubectl command to inspect the resource of each message in log output.")
+	analysisCmd.PersistentFlags().BoolVar(&showExamples, "examples", false,
+		"With --verbose, show example configuration for the type of each message in log output, where there is any.")
+	analysisCmd.PersistentFlags().BoolVar(&runMetadata, "run-metadata", false,
+		"Include the Istio version, time and cluster of the analysis in json, yaml and sarif output.")
+	analysisCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false,
+		"Replace non-ASCII characters in the output with ASCII equivalents, or escape them, for consumers that can't handle them.")
+	analysisCmd.PersistentFlags().IntVar(&maxMessageLength, "max-message-length", 0,
+		"Truncate message text to at most this many bytes in json and yaml output. Zero means no limit.")
 	analysisCmd.PersistentFlags().Var(&failureThreshold, "failure-threshold",
 		fmt.Sprintf("The severity level of analysis at which to set a non-zero exit code. Valid values: %v", diag.GetAllLevelStrings()))
 	analysisCmd.PersistentFlags().Var(&outputThreshold, "output-threshold",
 		fmt.Sprintf("The severity level of analysis at which to display messages. Valid values: %v", diag.GetAllLevelStrings()))
 	analysisCmd.PersistentFlags().StringVarP(&msgOutputFormat, "output", "o", formatting.LogFormat,
-		fmt.Sprintf("Output format: one of %v", formatting.MsgOutputFormatKeys))
+		fmt.Sprintf("Output format: one of %v. Defaults to $ISTIO_ANALYZE_FORMAT if set", formatting.MsgOutputFormatKeys))
 	analysisCmd.PersistentFlags().StringVar(&meshCfgFile, "meshConfigFile", "",
 		"Overrides the mesh config values to use for analysis.")
 	analysisCmd.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
@@ -315,6 +389,24 @@ func Analyze() *cobra.Command {
 	return analysisCmd
 }
 
+// analysisRunMetadata describes the current run of the analysis. The cluster is the kubeconfig context used, if the
+// live cluster is analyzed.
+func analysisRunMetadata() *diag.RunMetadata {
+	md := &diag.RunMetadata{
+		IstioVersion: version.Info.Version,
+		Timestamp:    time.Now(),
+	}
+	if useKube {
+		md.Cluster = configContext
+		if md.Cluster == "" {
+			if cfg, err := kube.BuildClientCmd(kubeconfig, "").RawConfig(); err == nil {
+				md.Cluster = cfg.CurrentContext
+			}
+		}
+	}
+	return md
+}
+
 func gatherFiles(cmd *cobra.Command, args []string) ([]local.ReaderSource, error) {
 	var readers []local.ReaderSource
 	for _, f := range args {
@@ -442,6 +534,40 @@ func AnalyzersAsString(analyzers []analysis.Analyzer) string {
 	return b.String()
 }
 
+// printMessageCatalog prints the known message types, filtered by the output threshold and message category. The log
+// format prints them as a table.
+func printMessageCatalog(cmd *cobra.Command) error {
+	var format string
+	switch msgOutputFormat {
+	case formatting.LogFormat:
+		format = diag.CatalogTableFormat
+	case formatting.JSONFormat:
+		format = diag.CatalogJSONFormat
+	case formatting.YAMLFormat:
+		format = diag.CatalogYAMLFormat
+	default:
+		return fmt.Errorf("--list-messages supports the output formats %s, %s and %s, but got %q",
+			formatting.LogFormat, formatting.JSONFormat, formatting.YAMLFormat, msgOutputFormat)
+	}
+	filter := diag.CatalogFilter{MinLevel: &outputThreshold.Level, Category: messageCategory}
+	output, err := diag.PrintCatalog(filter.Apply(msg.All()), format)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), output)
+	return nil
+}
+
+// printRulesManifest prints each analyzer along with the messages it can report as JSON
+func printRulesManifest(cmd *cobra.Command) error {
+	output, err := json.MarshalIndent(analysis.RulesManifest(analyzers.All()...), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	return nil
+}
+
 func analyzeTargetAsString() string {
 	if allNamespaces {
 		return "all namespaces"
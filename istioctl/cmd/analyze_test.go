@@ -15,11 +15,14 @@
 package cmd
 
 import (
+	"bytes"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
 
 	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/istioctl/pkg/util/formatting"
 )
 
 func TestErrorOnIssuesFound(t *testing.T) {
@@ -63,3 +66,27 @@ func TestNoErrorIfMessageLevelsBelowThreshold(t *testing.T) {
 
 	g.Expect(err).To(BeNil())
 }
+
+func TestPrintMessageCatalogFormats(t *testing.T) {
+	g := NewWithT(t)
+
+	prev := msgOutputFormat
+	t.Cleanup(func() { msgOutputFormat = prev })
+
+	for format, prefix := range map[string]string{
+		formatting.LogFormat:  "CODE",
+		formatting.JSONFormat: "[",
+		formatting.YAMLFormat: "- ",
+	} {
+		msgOutputFormat = format
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+		g.Expect(printMessageCatalog(cmd)).To(Succeed())
+		g.Expect(out.String()).To(HavePrefix(prefix), format)
+	}
+
+	// Other formats would silently print something else than requested
+	msgOutputFormat = formatting.SARIFFormat
+	g.Expect(printMessageCatalog(&cobra.Command{})).To(MatchError(ContainSubstring(`but got "sarif"`)))
+}
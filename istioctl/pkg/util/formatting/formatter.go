@@ -15,6 +15,7 @@
 package formatting
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,60 +26,299 @@ import (
 	"github.com/mattn/go-isatty"
 
 	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
 	"istio.io/pkg/env"
 )
 
-// Formatting options for Messages
+// Formatting options for Messages. Formats other than log, json and yaml are looked up in the diag formatter
+// registry, which also includes any formats registered with diag.RegisterFormatter.
 const (
-	LogFormat  = "log"
-	JSONFormat = "json"
-	YAMLFormat = "yaml"
+	LogFormat     = "log"
+	JSONFormat    = "json"
+	YAMLFormat    = "yaml"
+	SARIFFormat   = "sarif"
+	TreeFormat    = "tree"
+	CompactFormat = "compact"
+	JUnitFormat   = "junit"
+	HTMLFormat    = "html"
+	DigestFormat  = "digest"
+	GitLabFormat  = "gitlab"
+	SummaryFormat = "summary"
 )
 
 var (
-	MsgOutputFormatKeys = []string{LogFormat, JSONFormat, YAMLFormat}
-	MsgOutputFormats    = make(map[string]bool)
+	MsgOutputFormatKeys = append([]string{LogFormat, JSONFormat, YAMLFormat}, diag.FormatterNames()...)
 	termEnvVar          = env.RegisterStringVar("TERM", "", "Specifies terminal type.  Use 'dumb' to suppress color output")
+	formatEnvVar        = env.RegisterStringVar("ISTIO_ANALYZE_FORMAT", "",
+		fmt.Sprintf("Specifies the default output format for analysis messages. One of %v", MsgOutputFormatKeys))
 )
 
+// MsgOutputFormats holds the output formats known when this package was initialized.
+//
+// Deprecated: use diag.FormatterNames.
+var MsgOutputFormats = make(map[string]bool)
+
 func init() {
 	for _, key := range MsgOutputFormatKeys {
 		MsgOutputFormats[key] = true
 	}
 }
 
+// OutputFormats returns the names of all output formats, including those registered with diag.RegisterFormatter
+// after this package was initialized.
+func OutputFormats() []string {
+	return append([]string{LogFormat, JSONFormat, YAMLFormat}, diag.FormatterNames()...)
+}
+
+// IsOutputFormat returns whether the given name is a valid output format.
+func IsOutputFormat(name string) bool {
+	switch name {
+	case LogFormat, JSONFormat, YAMLFormat:
+		return true
+	}
+	_, ok := diag.FormatterByName(name)
+	return ok
+}
+
+// RenderOptions controls how messages are rendered
+type RenderOptions struct {
+	// Colorize renders the level of each message with terminal color codes
+	Colorize bool
+
+	// Verbose includes additional detail, such as related resources, beneath each message
+	Verbose bool
+
+	// Hyperlinks wraps the code of each message in an OSC 8 terminal hyperlink to its documentation URL. Messages
+	// whose type has no URL are rendered as plain text.
+	Hyperlinks bool
+
+	// Source, if set, returns the content of the file at the given path. In verbose mode, messages on resources
+	// loaded from files then include an excerpt of the file around the line they refer to.
+	Source func(path string) ([]byte, error)
+
+	// Numbered sorts the messages and prefixes each with its 1-based position, so that it can be referred to. The JSON
+	// and YAML formats include the same position as index.
+	Numbered bool
+
+	// MaxMessageLength, if positive, truncates the text of each message in the JSON and YAML formats to at most this
+	// many bytes. Truncated messages record the byte length of the original text as messageLength.
+	MaxMessageLength int
+
+	// RequireOrigin fails rendering if any message has no resource origin, rather than printing it without a location.
+	// It is intended for analyzer developers, as every analyzer should attribute its messages to a resource.
+	RequireOrigin bool
+
+	// OmitOrigin leaves the resources out of the log format, printing only the level, code and text of each message,
+	// for terse output or to avoid revealing resource names. In verbose mode, related resources and source excerpts
+	// are left out too. Other formats are machine readable, so always include the resources.
+	OmitOrigin bool
+
+	// KubectlCommands adds a kubectl command printing the resource of each message beneath it in verbose mode, so
+	// that it can be inspected. It only applies to the log format, and not when the origin is omitted.
+	KubectlCommands bool
+
+	// Examples adds the example configuration declared in messages.yaml for the type of each message beneath it in
+	// verbose mode, to show how to resolve it. It only applies to the log format.
+	Examples bool
+
+	// Metadata, if set, describes the run in machine readable output. The JSON and YAML formats then become an object
+	// with the metadata under "metadata" and the messages under "messages", rather than a list of messages. The SARIF
+	// format includes it in the property bag of the run. Other formats ignore it.
+	Metadata *diag.RunMetadata
+
+	// ASCII replaces any non-ASCII characters in the output with ASCII equivalents or escapes, as done by
+	// diag.ASCIIFold, for consumers that can't handle them. It applies to all formats. The strings of structured
+	// formats are folded before they are serialized, so that the output stays valid, e.g. typographic quotes in the
+	// arguments of a message don't end JSON strings.
+	ASCII bool
+}
+
+// DefaultOutputFormat returns the output format to use when none is specified explicitly. This is the value of
+// $ISTIO_ANALYZE_FORMAT if it is set, or LogFormat otherwise.
+func DefaultOutputFormat() (string, error) {
+	format := strings.ToLower(formatEnvVar.Get())
+	if format == "" {
+		return LogFormat, nil
+	}
+	if !IsOutputFormat(format) {
+		return "", fmt.Errorf("invalid format in $%s, expected one of %v but got %q", formatEnvVar.Name, OutputFormats(), format)
+	}
+	return format, nil
+}
+
 // Print output messages in the specified format with color options
 func Print(ms diag.Messages, format string, colorize bool) (string, error) {
+	return PrintWithOptions(ms, format, RenderOptions{Colorize: colorize})
+}
+
+// PrintWithOptions output messages in the specified format with the given rendering options
+func PrintWithOptions(ms diag.Messages, format string, opts RenderOptions) (string, error) {
+	if opts.RequireOrigin {
+		if err := ms.ValidateOrigins(); err != nil {
+			return "", err
+		}
+	}
+	if opts.Numbered && ms != nil {
+		// Sort a copy, so that the numbering only depends on the messages and not the order they were found in
+		ms = append(ms[:0:0], ms...)
+		ms.Sort()
+	}
+
 	switch format {
 	case LogFormat:
-		return printLog(ms, colorize), nil
+		out := printLog(ms, opts)
+		if opts.ASCII {
+			out = diag.ASCIIFold(out)
+		}
+		return out, nil
 	case JSONFormat:
-		return printJSON(ms)
+		return printJSON(ms, opts)
 	case YAMLFormat:
-		return printYAML(ms)
+		return printYAML(ms, opts)
 	default:
-		return "", fmt.Errorf("invalid format, expected one of %v but got %q", MsgOutputFormatKeys, format)
+		f, ok := diag.FormatterByName(format)
+		if !ok {
+			return "", fmt.Errorf("invalid format, expected one of %v but got %q", OutputFormats(), format)
+		}
+		if sf, ok := f.(diag.SARIFFormatter); ok && opts.Metadata != nil {
+			sf.Metadata = opts.Metadata
+			f = sf
+		}
+		if !opts.ASCII {
+			return f.Format(ms)
+		}
+		// The formatters serialize the messages themselves, so approximate their arguments, which are the most likely
+		// source of characters that fold to syntax, beforehand and only escape the rest of the output.
+		out, err := f.Format(ms.Apply(approximateParameters))
+		return diag.ASCIIFold(out), err
+	}
+}
+
+// approximateParameters returns a copy of m with diag.ASCIIApproximate applied to its string parameters.
+func approximateParameters(m diag.Message) diag.Message {
+	params := make([]interface{}, len(m.Parameters))
+	for i, p := range m.Parameters {
+		if s, ok := p.(string); ok {
+			p = diag.ASCIIApproximate(s)
+		}
+		params[i] = p
 	}
+	m.Parameters = params
+	return m
 }
 
-func printLog(ms diag.Messages, colorize bool) string {
+func printLog(ms diag.Messages, opts RenderOptions) string {
 	var logOutput []string
-	for _, m := range ms {
-		logOutput = append(logOutput, render(m, colorize))
+	for i, m := range ms {
+		out := render(m, opts)
+		if opts.Numbered {
+			out = fmt.Sprintf("#%d %s", i+1, out)
+		}
+		logOutput = append(logOutput, out)
 	}
 	return strings.Join(logOutput, "\n")
 }
 
-func printJSON(ms diag.Messages) (string, error) {
-	jsonOutput, err := json.MarshalIndent(ms, "", "\t")
-	return string(jsonOutput), err
+func printJSON(ms diag.Messages, opts RenderOptions) (string, error) {
+	var v interface{} = envelope(ms, opts)
+	if opts.ASCII {
+		// Non-ASCII characters can only remain inside strings, where the escapes of diag.ASCIIFold are valid JSON
+		var err error
+		if v, err = foldStrings(v, diag.ASCIIApproximate); err != nil {
+			return "", err
+		}
+	}
+	jsonOutput, err := json.MarshalIndent(v, "", "\t")
+	if err != nil || !opts.ASCII {
+		return string(jsonOutput), err
+	}
+	return diag.ASCIIFold(string(jsonOutput)), nil
 }
 
-func printYAML(ms diag.Messages) (string, error) {
-	yamlOutput, err := yaml.Marshal(ms)
+func printYAML(ms diag.Messages, opts RenderOptions) (string, error) {
+	var v interface{} = envelope(ms, opts)
+	if opts.ASCII {
+		var err error
+		if v, err = foldStrings(v, diag.ASCIIFold); err != nil {
+			return "", err
+		}
+	}
+	yamlOutput, err := yaml.Marshal(v)
 	return string(yamlOutput), err
 }
 
+// foldStrings returns v, converted to its JSON representation, with fold applied to all of its strings, including map
+// keys, so that they can be folded before being serialized.
+func foldStrings(v interface{}, fold func(string) string) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var decoded interface{}
+	if err := d.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return foldDecoded(decoded, fold), nil
+}
+
+func foldDecoded(v interface{}, fold func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return fold(v)
+	case []interface{}:
+		for i, e := range v {
+			v[i] = foldDecoded(e, fold)
+		}
+		return v
+	case map[string]interface{}:
+		folded := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			folded[fold(k)] = foldDecoded(e, fold)
+		}
+		return folded
+	default:
+		return v
+	}
+}
+
+// envelope returns the value to serialize for the JSON and YAML formats: the list of messages, or an object holding
+// the run metadata alongside the messages if there is any
+func envelope(ms diag.Messages, opts RenderOptions) interface{} {
+	messages := unstructured(ms, opts)
+	if opts.Metadata == nil {
+		return messages
+	}
+	if messages == nil {
+		messages = []map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"metadata": opts.Metadata.Unstructured(),
+		"messages": messages,
+	}
+}
+
+// unstructured returns the messages as unstructured maps for serialization, truncating long message text
+func unstructured(ms diag.Messages, opts RenderOptions) []map[string]interface{} {
+	if ms == nil {
+		return nil
+	}
+	result := make([]map[string]interface{}, 0, len(ms))
+	for i, m := range ms {
+		u := m.Unstructured(true)
+		if opts.Numbered {
+			u["index"] = i + 1
+		}
+		if text := m.Text(); opts.MaxMessageLength > 0 && len(text) > opts.MaxMessageLength {
+			u["message"] = diag.Truncate(text, opts.MaxMessageLength)
+			u["messageLength"] = len(text)
+		}
+		result = append(result, u)
+	}
+	return result
+}
+
 // Formatting options for Message
 var (
 	colorPrefixes = map[diag.Level]string{
@@ -89,11 +329,85 @@ var (
 )
 
 // render turns a Message instance into a string with an option of colored bash output
-func render(m diag.Message, colorize bool) string {
-	return fmt.Sprintf("%s%v%s [%v]%s %s",
-		colorPrefix(m, colorize), m.Type.Level(), colorSuffix(colorize),
-		m.Type.Code(), m.Origin(), fmt.Sprintf(m.Type.Template(), m.Parameters...),
+func render(m diag.Message, opts RenderOptions) string {
+	origin := m.Origin()
+	if opts.OmitOrigin {
+		origin = ""
+	}
+	out := fmt.Sprintf("%s%v%s [%v]%s %s",
+		colorPrefix(m, opts.Colorize), m.Type.Level(), colorSuffix(opts.Colorize),
+		renderCode(m, opts.Hyperlinks), origin, m.Text(),
 	)
+	if opts.Verbose {
+		if !opts.OmitOrigin {
+			if excerpt := sourceExcerpt(m, opts.Source); excerpt != "" {
+				out += "\n" + indent(excerpt, "\t")
+			}
+			for _, related := range m.RelatedOrigins() {
+				out += "\n\tRelated: " + related
+			}
+			if cmd := m.KubectlCommand(); opts.KubectlCommands && cmd != "" {
+				out += "\n\tInspect with: " + cmd
+			}
+		}
+		if fix := m.SuggestedFix; fix != nil {
+			out += "\n\tSuggested fix: " + fix.Description
+			if fix.Patch != "" {
+				out += "\n\t\tPatch: " + fix.Patch
+			}
+			if fix.Replacement != "" {
+				out += "\n\t\tReplacement:\n" + indent(fix.Replacement, "\t\t\t")
+			}
+		}
+		if opts.Examples {
+			for _, e := range msg.ExamplesFor(m.Type.Code()) {
+				out += "\n\tExample: " + e.Description
+				if e.Bad != "" {
+					out += "\n\t\tBad:\n" + indent(e.Bad, "\t\t\t")
+				}
+				if e.Good != "" {
+					out += "\n\t\tGood:\n" + indent(e.Good, "\t\t\t")
+				}
+			}
+		}
+		if m.Stack != "" {
+			out += "\n\tStack:\n" + indent(m.Stack, "\t\t")
+		}
+	}
+	return out
+}
+
+// sourceExcerptContext is the number of lines shown either side of the line a message refers to
+const sourceExcerptContext = 2
+
+// sourceExcerpt returns an excerpt of the source file around the position of the message, or empty if the message
+// has no position or the source isn't available
+func sourceExcerpt(m diag.Message, source func(string) ([]byte, error)) string {
+	if source == nil {
+		return ""
+	}
+	path, line := m.Position()
+	if line == 0 {
+		return ""
+	}
+	content, err := source(path)
+	if err != nil {
+		return ""
+	}
+	return diag.Excerpt(content, line, sourceExcerptContext)
+}
+
+// indent prefixes each line of s with the given prefix
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+prefix)
+}
+
+// renderCode returns the code of the message, optionally as an OSC 8 hyperlink to its documentation
+func renderCode(m diag.Message, hyperlinks bool) string {
+	if !hyperlinks || m.Type.URL() == "" {
+		return m.Type.Code()
+	}
+	return "\033]8;;" + m.Type.URL() + "\033\\" + m.Type.Code() + "\033]8;;\033\\"
 }
 
 func colorPrefix(m diag.Message, colorize bool) string {
@@ -15,11 +15,21 @@
 package formatting
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ghodss/yaml"
 	. "github.com/onsi/gomega"
 
 	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/source/kube/rt"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collections"
 	"istio.io/istio/pkg/url"
 )
 
@@ -69,6 +79,97 @@ func TestFormatter_PrintLogWithColor(t *testing.T) {
 	))
 }
 
+func TestFormatter_PrintLogVerbose(t *testing.T) {
+	g := NewWithT(t)
+
+	firstMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		diag.MockResource("SoapBubble"),
+		"the bubble is too big",
+	)
+	firstMsg.Related = []*resource.Instance{diag.MockResource("Bathtub")}
+	firstMsg.SuggestedFix = &diag.SuggestedFix{
+		Description: "Use a smaller bubble",
+		Patch:       `[{"op": "replace", "path": "/spec/size", "value": 1}]`,
+		Replacement: "spec:\n  size: 1\n",
+	}
+
+	msgs := diag.Messages{firstMsg}
+	output, _ := PrintWithOptions(msgs, LogFormat, RenderOptions{Verbose: true})
+	g.Expect(output).To(Equal(
+		"Error [B1] (SoapBubble) Explosion accident: the bubble is too big\n" +
+			"\tRelated: Bathtub\n" +
+			"\tSuggested fix: Use a smaller bubble\n" +
+			"\t\tPatch: [{\"op\": \"replace\", \"path\": \"/spec/size\", \"value\": 1}]\n" +
+			"\t\tReplacement:\n" +
+			"\t\t\tspec:\n" +
+			"\t\t\t  size: 1",
+	))
+
+	output, _ = PrintWithOptions(msgs, LogFormat, RenderOptions{})
+	g.Expect(output).To(Equal("Error [B1] (SoapBubble) Explosion accident: the bubble is too big"))
+}
+
+func TestFormatter_PrintLogVerboseWithSource(t *testing.T) {
+	g := NewWithT(t)
+
+	msg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		&resource.Instance{Origin: &rt.Origin{
+			Kind:     "Bubble",
+			FullName: resource.NewFullName("default", "soap"),
+			Ref:      &rt.Position{Filename: "bubbles.yaml", Line: 4},
+		}},
+		"the bubble is too big",
+	)
+	source := func(path string) ([]byte, error) {
+		if path != "bubbles.yaml" {
+			return nil, os.ErrNotExist
+		}
+		return []byte("kind: Bubble\nmetadata:\n  name: soap\nspec:\n  size: 100\n"), nil
+	}
+
+	output, _ := PrintWithOptions(diag.Messages{msg}, LogFormat, RenderOptions{Verbose: true, Source: source})
+	g.Expect(output).To(Equal(
+		"Error [B1] (Bubble soap.default bubbles.yaml:4) Explosion accident: the bubble is too big\n" +
+			"\t  2 | metadata:\n" +
+			"\t  3 |   name: soap\n" +
+			"\t> 4 | spec:\n" +
+			"\t    | ^\n" +
+			"\t  5 |   size: 100",
+	))
+
+	// Without the source, or outside of verbose mode, only the origin is shown
+	noSource := func(string) ([]byte, error) { return nil, os.ErrNotExist }
+	output, _ = PrintWithOptions(diag.Messages{msg}, LogFormat, RenderOptions{Verbose: true, Source: noSource})
+	g.Expect(output).To(Equal("Error [B1] (Bubble soap.default bubbles.yaml:4) Explosion accident: the bubble is too big"))
+	output, _ = PrintWithOptions(diag.Messages{msg}, LogFormat, RenderOptions{Source: source})
+	g.Expect(output).To(Equal("Error [B1] (Bubble soap.default bubbles.yaml:4) Explosion accident: the bubble is too big"))
+}
+
+func TestFormatter_PrintLogWithHyperlinks(t *testing.T) {
+	g := NewWithT(t)
+
+	firstMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v", diag.WithURL("https://example.com/b1")),
+		diag.MockResource("SoapBubble"),
+		"the bubble is too big",
+	)
+	secondMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Warning, "C1", "Collapse danger: %v"),
+		diag.MockResource("GrandCastle"),
+		"the castle is too old",
+	)
+
+	msgs := diag.Messages{firstMsg, secondMsg}
+	output, _ := PrintWithOptions(msgs, LogFormat, RenderOptions{Hyperlinks: true})
+
+	g.Expect(output).To(Equal(
+		"Error [\033]8;;https://example.com/b1\033\\B1\033]8;;\033\\] (SoapBubble) Explosion accident: the bubble is too big\n" +
+			"Warning [C1] (GrandCastle) Collapse danger: the castle is too old",
+	))
+}
+
 func TestFormatter_PrintJSON(t *testing.T) {
 	g := NewWithT(t)
 
@@ -138,6 +239,49 @@ func TestFormatter_PrintYAML(t *testing.T) {
 	g.Expect(output).To(Equal(expectedOutput))
 }
 
+func TestFormatter_PrintMaxMessageLength(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{
+		diag.NewMessage(
+			diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+			diag.MockResource("SoapBubble"),
+			"the bubble is too big",
+		),
+		diag.NewMessage(
+			diag.NewMessageType(diag.Warning, "C1", "Collapse: %v"),
+			diag.MockResource("GrandCastle"),
+			"old",
+		),
+	}
+	opts := RenderOptions{MaxMessageLength: 16}
+
+	output, err := PrintWithOptions(msgs, YAMLFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`- code: B1
+  documentationUrl: ` + url.ConfigAnalysis + `/b1/
+  level: Error
+  message: Explosion acc...
+  messageLength: 41
+  origin: SoapBubble
+- code: C1
+  documentationUrl: ` + url.ConfigAnalysis + `/c1/
+  level: Warning
+  message: 'Collapse: old'
+  origin: GrandCastle
+`))
+
+	output, err = PrintWithOptions(msgs, JSONFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring(`"message": "Explosion acc...",`))
+	g.Expect(output).To(ContainSubstring(`"messageLength": 41,`))
+
+	// The log format is not truncated
+	output, err = PrintWithOptions(msgs, LogFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring("the bubble is too big"))
+}
+
 func TestFormatter_PrintEmpty(t *testing.T) {
 	g := NewWithT(t)
 
@@ -152,3 +296,337 @@ func TestFormatter_PrintEmpty(t *testing.T) {
 	yamlOutput, _ := Print(msgs, YAMLFormat, false)
 	g.Expect(yamlOutput).To(Equal("[]\n"))
 }
+
+func TestFormatter_DefaultOutputFormat(t *testing.T) {
+	g := NewWithT(t)
+	defer os.Unsetenv(formatEnvVar.Name)
+
+	format, err := DefaultOutputFormat()
+	g.Expect(err).To(BeNil())
+	g.Expect(format).To(Equal(LogFormat))
+
+	os.Setenv(formatEnvVar.Name, "JSON")
+	format, err = DefaultOutputFormat()
+	g.Expect(err).To(BeNil())
+	g.Expect(format).To(Equal(JSONFormat))
+
+	os.Setenv(formatEnvVar.Name, "bogus")
+	_, err = DefaultOutputFormat()
+	g.Expect(err).To(MatchError(ContainSubstring(`expected one of [log json yaml sarif tree compact junit`)))
+}
+
+func TestFormatter_PrintNumbered(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{
+		diag.NewMessage(
+			diag.NewMessageType(diag.Warning, "C1", "Collapse danger: %v"),
+			diag.MockResource("GrandCastle"),
+			"the castle is too old",
+		),
+		diag.NewMessage(
+			diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+			diag.MockResource("SoapBubble"),
+			"the bubble is too big",
+		),
+	}
+	opts := RenderOptions{Numbered: true}
+
+	output, err := PrintWithOptions(msgs, LogFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"#1 Error [B1] (SoapBubble) Explosion accident: the bubble is too big\n" +
+			"#2 Warning [C1] (GrandCastle) Collapse danger: the castle is too old",
+	))
+
+	// The input is left in its original order
+	g.Expect(msgs[0].Type.Code()).To(Equal("C1"))
+
+	output, err = PrintWithOptions(msgs, YAMLFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`- code: B1
+  documentationUrl: ` + url.ConfigAnalysis + `/b1/
+  index: 1
+  level: Error
+  message: 'Explosion accident: the bubble is too big'
+  origin: SoapBubble
+- code: C1
+  documentationUrl: ` + url.ConfigAnalysis + `/c1/
+  index: 2
+  level: Warning
+  message: 'Collapse danger: the castle is too old'
+  origin: GrandCastle
+`))
+}
+
+type ticketFormatter struct{}
+
+// registerTicketFormatter registers ticketFormatter once per test binary, since the diag formatter registry is global
+// and rejects duplicate names.
+var registerTicketFormatter sync.Once
+
+func (ticketFormatter) Format(ms diag.Messages) (string, error) {
+	return fmt.Sprintf("%d tickets", len(ms)), nil
+}
+
+func TestFormatter_PrintRegisteredFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		diag.MockResource("SoapBubble"),
+		"the bubble is too big",
+	)}
+
+	g.Expect(IsOutputFormat("unregistered")).To(BeFalse())
+	_, err := Print(msgs, "unregistered", false)
+	g.Expect(err).To(HaveOccurred())
+
+	registerTicketFormatter.Do(func() {
+		g.Expect(diag.RegisterFormatter("ticket", ticketFormatter{})).To(Succeed())
+	})
+	g.Expect(IsOutputFormat("ticket")).To(BeTrue())
+	g.Expect(OutputFormats()).To(ContainElement("ticket"))
+	output, err := Print(msgs, "ticket", false)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("1 tickets"))
+}
+
+func TestFormatter_PrintRequireOrigin(t *testing.T) {
+	g := NewWithT(t)
+
+	bubble := diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v")
+	castle := diag.NewMessageType(diag.Warning, "C1", "Collapse danger: %v")
+	msgs := diag.Messages{
+		diag.NewMessage(bubble, diag.MockResource("SoapBubble"), "the bubble is too big"),
+	}
+	opts := RenderOptions{RequireOrigin: true}
+
+	output, err := PrintWithOptions(msgs, LogFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("Error [B1] (SoapBubble) Explosion accident: the bubble is too big"))
+
+	msgs.Add(
+		diag.NewMessage(castle, nil, "the castle is too old"),
+		diag.NewMessage(bubble, nil, "the bubble is too big"),
+	)
+	_, err = PrintWithOptions(msgs, JSONFormat, opts)
+	g.Expect(err).To(MatchError("messages with codes B1, C1 have no resource origin"))
+
+	// Without the option, messages without an origin are printed without a location
+	_, err = PrintWithOptions(msgs, LogFormat, RenderOptions{})
+	g.Expect(err).To(BeNil())
+}
+
+func TestFormatter_PrintOmitOrigin(t *testing.T) {
+	g := NewWithT(t)
+
+	msg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		diag.MockResource("SoapBubble"),
+		"the bubble is too big",
+	)
+	msg.Related = []*resource.Instance{diag.MockResource("Bathtub")}
+	msg.SuggestedFix = &diag.SuggestedFix{Description: "Use a smaller bubble"}
+	msgs := diag.Messages{msg}
+
+	output, err := PrintWithOptions(msgs, LogFormat, RenderOptions{OmitOrigin: true, Verbose: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"Error [B1] Explosion accident: the bubble is too big\n" +
+			"\tSuggested fix: Use a smaller bubble",
+	))
+
+	// Machine readable formats keep the origin
+	output, err = PrintWithOptions(msgs, JSONFormat, RenderOptions{OmitOrigin: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring(`"origin": "SoapBubble"`))
+}
+
+func TestFormatter_PrintKubectlCommands(t *testing.T) {
+	g := NewWithT(t)
+
+	r := diag.MockResource("reviews")
+	r.Metadata.FullName = resource.NewFullName("prod", "reviews")
+	r.Metadata.Schema = collections.IstioNetworkingV1Alpha3Virtualservices.Resource()
+	msgs := diag.Messages{diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		r,
+		"the bubble is too big",
+	)}
+
+	output, err := PrintWithOptions(msgs, LogFormat, RenderOptions{Verbose: true, KubectlCommands: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"Error [B1] (reviews) Explosion accident: the bubble is too big\n" +
+			"\tInspect with: kubectl get virtualservices.networking.istio.io reviews -n prod -o yaml",
+	))
+
+	// The command is only shown in verbose mode
+	output, err = PrintWithOptions(msgs, LogFormat, RenderOptions{KubectlCommands: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("Error [B1] (reviews) Explosion accident: the bubble is too big"))
+}
+
+func TestFormatter_PrintLogVerboseStack(t *testing.T) {
+	g := NewWithT(t)
+
+	m := diag.NewMessage(diag.NewMessageType(diag.Error, "B1", "Internal error: %v"), nil, "analyzer panicked")
+	m.Stack = "goroutine 1 [running]:\nmain.main()\n"
+	msgs := diag.Messages{m}
+
+	output, err := PrintWithOptions(msgs, LogFormat, RenderOptions{Verbose: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(
+		"Error [B1] Internal error: analyzer panicked\n" +
+			"\tStack:\n" +
+			"\t\tgoroutine 1 [running]:\n" +
+			"\t\tmain.main()",
+	))
+
+	// The stack is only shown in verbose mode
+	output, err = PrintWithOptions(msgs, LogFormat, RenderOptions{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("Error [B1] Internal error: analyzer panicked"))
+}
+
+func TestFormatter_PrintLogVerboseExamples(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{msg.NewUnknownAnnotation(nil, "networking.istio.io/exportToo")}
+
+	output, err := PrintWithOptions(msgs, LogFormat, RenderOptions{Verbose: true, Examples: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(HavePrefix(
+		"Warning [IST0108] Unknown annotation: networking.istio.io/exportToo\n" +
+			"\tExample: Annotations must be spelt exactly as documented\n" +
+			"\t\tBad:\n" +
+			"\t\t\tapiVersion: v1\n",
+	))
+	g.Expect(output).To(ContainSubstring("\t\tGood:\n\t\t\tapiVersion: v1\n"))
+	g.Expect(output).To(HaveSuffix("\t\t\t    networking.istio.io/exportTo: \".\""))
+
+	// Examples are only shown in verbose mode
+	output, err = PrintWithOptions(msgs, LogFormat, RenderOptions{Examples: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("Warning [IST0108] Unknown annotation: networking.istio.io/exportToo"))
+}
+
+func TestFormatter_PrintMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		diag.MockResource("SoapBubble"),
+		"the bubble is too big",
+	)}
+	opts := RenderOptions{Metadata: &diag.RunMetadata{
+		IstioVersion: "1.9.0",
+		Timestamp:    time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC),
+		Cluster:      "kind-istio",
+	}}
+
+	output, err := PrintWithOptions(msgs, YAMLFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`messages:
+- code: B1
+  documentationUrl: ` + url.ConfigAnalysis + `/b1/
+  level: Error
+  message: 'Explosion accident: the bubble is too big'
+  origin: SoapBubble
+metadata:
+  cluster: kind-istio
+  istioVersion: 1.9.0
+  timestamp: "2021-02-03T04:05:06Z"
+`))
+
+	output, err = PrintWithOptions(nil, JSONFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`{
+	"messages": [],
+	"metadata": {
+		"cluster": "kind-istio",
+		"istioVersion": "1.9.0",
+		"timestamp": "2021-02-03T04:05:06Z"
+	}
+}`))
+
+	output, err = PrintWithOptions(msgs, SARIFFormat, opts)
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring(`"properties": {
+        "metadata": {
+          "cluster": "kind-istio",
+          "istioVersion": "1.9.0",
+          "timestamp": "2021-02-03T04:05:06Z"
+        }
+      }`))
+
+	// Without metadata, the messages are printed as a list
+	output, err = PrintWithOptions(nil, JSONFormat, RenderOptions{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("null"))
+}
+
+func TestFormatter_PrintASCII(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Host not found: %v"),
+		diag.MockResource("SoapBubble"),
+		"café.例.com",
+	)}
+
+	output, err := PrintWithOptions(msgs, LogFormat, RenderOptions{})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal("Error [B1] (SoapBubble) Host not found: café.例.com"))
+
+	output, err = PrintWithOptions(msgs, LogFormat, RenderOptions{ASCII: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(Equal(`Error [B1] (SoapBubble) Host not found: cafe.\u4f8b.com`))
+
+	output, err = PrintWithOptions(msgs, JSONFormat, RenderOptions{ASCII: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(output).To(ContainSubstring(`"message": "Host not found: cafe.\u4f8b.com"`))
+}
+
+func TestFormatter_PrintASCIIRoundTrip(t *testing.T) {
+	g := NewWithT(t)
+
+	msgs := diag.Messages{diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Annotation %s bad", diag.WithArgs("annotation")),
+		diag.MockResource("SoapBubble"),
+		"“quoted” 例",
+	)}
+
+	// Typographic quotes fold to quotes that must still be escaped by the serializer
+	output, err := PrintWithOptions(msgs, JSONFormat, RenderOptions{ASCII: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(isASCII(output)).To(BeTrue())
+	var fromJSON []map[string]interface{}
+	g.Expect(json.Unmarshal([]byte(output), &fromJSON)).To(Succeed())
+	g.Expect(fromJSON[0]["message"]).To(Equal(`Annotation "quoted" 例 bad`))
+	g.Expect(fromJSON[0]["args"]).To(Equal(map[string]interface{}{"annotation": `"quoted" 例`}))
+
+	output, err = PrintWithOptions(msgs, YAMLFormat, RenderOptions{ASCII: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(isASCII(output)).To(BeTrue())
+	var fromYAML []map[string]interface{}
+	g.Expect(yaml.Unmarshal([]byte(output), &fromYAML)).To(Succeed())
+	g.Expect(fromYAML[0]["message"]).To(Equal(`Annotation "quoted" \u4f8b bad`))
+
+	output, err = PrintWithOptions(msgs, SARIFFormat, RenderOptions{ASCII: true})
+	g.Expect(err).To(BeNil())
+	g.Expect(isASCII(output)).To(BeTrue())
+	var fromSARIF map[string]interface{}
+	g.Expect(json.Unmarshal([]byte(output), &fromSARIF)).To(Succeed())
+	g.Expect(output).To(ContainSubstring(`Annotation \"quoted\" \u4f8b bad`))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}